@@ -0,0 +1,114 @@
+package poseidon2
+
+import (
+	"github.com/brevis-network/pico/gnark/babybear"
+	picoposeidon2 "github.com/brevis-network/pico/gnark/poseidon2"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash"
+	"math/big"
+)
+
+// babybearLimbBits is the width used to chop a BN254 scalar into BabyBear
+// field elements; it's comfortably below BabyBear's 31-bit modulus so every
+// limb range-checks to a canonical element once reduced.
+const babybearLimbBits = 30
+const bn254Bits = 254
+
+// babybearModulus is BabyBear's prime (2^31-2^27+1), used as the UpperBound
+// for SetState's incoming lanes. It's larger than 1<<babybearLimbBits
+// (~1.875x), so the limb bound that's correct for splitToLimbs' chopped
+// 30-bit chunks would be unsound here: a canonical BabyBear element can
+// exceed 2^30, and ReduceSlow with too tight an UpperBound doesn't actually
+// bound the value it reduces.
+var babybearModulus = big.NewInt(2013265921)
+
+// Poseidon2BabyBearHasher adapts the shared pico/gnark/poseidon2
+// Poseidon2BabyBearChip's sponge to gnark's hash.StateStorer/FieldHasher
+// interfaces so it can stand in for MiMC in Fiat-Shamir transcripts and
+// recursion.NewShort-style short hashes that only know how to absorb/
+// squeeze BN254 frontend.Variables. A BN254 scalar is absorbed by
+// range-checking it into babybearLimbBits-wide limbs and feeding those
+// limbs through the BabyBear sponge; Sum squeezes one BabyBear lane per
+// output limb and repacks them into a BN254 scalar with a
+// domain-separating high bit so a hasher digest can't collide with a
+// directly-packed input of the same shape.
+type Poseidon2BabyBearHasher struct {
+	api      frontend.API
+	chip     *picoposeidon2.Poseidon2BabyBearChip
+	fieldApi *babybear.Chip
+}
+
+var _ hash.StateStorer = (*Poseidon2BabyBearHasher)(nil)
+
+func NewBabyBearHasher(api frontend.API) *Poseidon2BabyBearHasher {
+	return &Poseidon2BabyBearHasher{api: api, chip: picoposeidon2.NewBabyBearChip(api), fieldApi: babybear.NewChip(api)}
+}
+
+func (h *Poseidon2BabyBearHasher) Write(data ...frontend.Variable) {
+	for _, d := range data {
+		for _, limb := range h.splitToLimbs(d) {
+			h.chip.Update(limb)
+		}
+	}
+}
+
+func (h *Poseidon2BabyBearHasher) Sum() frontend.Variable {
+	state := h.chip.Finalize()
+	return h.packLimbs(state[:numPackedLimbs])
+}
+
+func (h *Poseidon2BabyBearHasher) Reset() {
+	h.chip = picoposeidon2.NewBabyBearChip(h.api)
+}
+
+func (h *Poseidon2BabyBearHasher) State() []frontend.Variable {
+	state := make([]frontend.Variable, picoposeidon2.BABYBEAR_WIDTH)
+	for i, lane := range h.chip.State {
+		state[i] = lane.Value
+	}
+	return state
+}
+
+func (h *Poseidon2BabyBearHasher) SetState(state []frontend.Variable) error {
+	if len(state) != picoposeidon2.BABYBEAR_WIDTH {
+		return frontend.ErrInputNotSet
+	}
+	for i, v := range state {
+		h.chip.State[i] = h.fieldApi.ReduceSlow(babybear.Variable{Value: v, UpperBound: babybearModulus})
+	}
+	return nil
+}
+
+// numPackedLimbs is the number of BabyBear lanes folded into a single Sum();
+// numPackedLimbs*babybearLimbBits stays under the BN254 scalar field size.
+const numPackedLimbs = (bn254Bits - 1) / babybearLimbBits
+
+func (h *Poseidon2BabyBearHasher) splitToLimbs(v frontend.Variable) []babybear.Variable {
+	bits := h.api.ToBinary(v, bn254Bits)
+	limbBound := new(big.Int).Lsh(big.NewInt(1), babybearLimbBits)
+
+	limbs := make([]babybear.Variable, 0, (bn254Bits+babybearLimbBits-1)/babybearLimbBits)
+	for i := 0; i < bn254Bits; i += babybearLimbBits {
+		end := i + babybearLimbBits
+		if end > bn254Bits {
+			end = bn254Bits
+		}
+		value := h.api.FromBinary(bits[i:end]...)
+		limbs = append(limbs, h.fieldApi.ReduceSlow(babybear.Variable{Value: value, UpperBound: limbBound}))
+	}
+	return limbs
+}
+
+func (h *Poseidon2BabyBearHasher) packLimbs(limbs []babybear.Variable) frontend.Variable {
+	shift := big.NewInt(1)
+	limbShift := new(big.Int).Lsh(big.NewInt(1), babybearLimbBits)
+
+	packed := frontend.Variable(0)
+	for _, limb := range limbs {
+		packed = h.api.Add(packed, h.api.Mul(limb.Value, shift))
+		shift = new(big.Int).Mul(shift, limbShift)
+	}
+	// Domain separator: set the bit just above the packed limbs so a digest
+	// can never equal a raw value built from the same number of limbs.
+	return h.api.Add(packed, shift)
+}
@@ -0,0 +1,137 @@
+// Package merkle builds Merkle-tree accumulators over the same Poseidon2
+// permutation the BabyBear/KoalaBear verifier circuits already use, so a
+// downstream zk-VM verifier can commit to and prove (non-)membership of
+// vectors of field elements without pulling in a second hash function.
+package merkle
+
+import (
+	"math/big"
+
+	"github.com/brevis-network/pico/gnark/babybear"
+	"github.com/brevis-network/pico/gnark/poseidon2"
+	"github.com/consensys/gnark/frontend"
+)
+
+// Digest is a node of the tree: the full 16-lane Poseidon2BabyBearChip
+// sponge output, used for leaves and internal nodes alike.
+type Digest = [poseidon2.BABYBEAR_WIDTH]babybear.Variable
+
+// babybearModulus is BabyBear's prime (2^31-2^27+1), used to canonicalize
+// Key/Next values before they're compared - see VerifyNonInclusion.
+var babybearModulus = big.NewInt(2013265921)
+
+// Compress2to1 folds two child digests into their parent by absorbing both
+// through a fresh Poseidon2BabyBearChip sponge - the standard 2-to-1
+// compression function for a binary Merkle tree.
+func Compress2to1(api frontend.API, left, right Digest) Digest {
+	chip := poseidon2.NewBabyBearChip(api)
+	for _, limb := range left {
+		chip.Update(limb)
+	}
+	for _, limb := range right {
+		chip.Update(limb)
+	}
+	return chip.Finalize()
+}
+
+// Compress16to1 absorbs a full WIDTH-wide batch of children in one sponge,
+// for trees built with the full-state arity instead of binary compression.
+func Compress16to1(api frontend.API, children [poseidon2.BABYBEAR_WIDTH]Digest) Digest {
+	chip := poseidon2.NewBabyBearChip(api)
+	for _, child := range children {
+		for _, limb := range child {
+			chip.Update(limb)
+		}
+	}
+	return chip.Finalize()
+}
+
+func selectDigest(api frontend.API, bit frontend.Variable, onTrue, onFalse Digest) Digest {
+	var out Digest
+	for i := 0; i < poseidon2.BABYBEAR_WIDTH; i++ {
+		// onTrue[i] and onFalse[i] can carry different bounds (e.g. one
+		// side is a freshly-reduced leaf, the other a running Compress2to1
+		// output with a looser bound); the selected value could end up
+		// being either one depending on bit, so its bound must cover both,
+		// not just onTrue's.
+		bound := onTrue[i].UpperBound
+		if onFalse[i].UpperBound.Cmp(bound) > 0 {
+			bound = onFalse[i].UpperBound
+		}
+		out[i] = babybear.Variable{
+			Value:      api.Select(bit, onTrue[i].Value, onFalse[i].Value),
+			UpperBound: bound,
+		}
+	}
+	return out
+}
+
+func assertDigestEqual(api frontend.API, a, b Digest) {
+	for i := 0; i < poseidon2.BABYBEAR_WIDTH; i++ {
+		api.AssertIsEqual(a[i].Value, b[i].Value)
+	}
+}
+
+// VerifyInclusion recomputes the root by compressing leaf with each sibling
+// in path, using indexBits[i] == 1 to mean "leaf's current node is the
+// right child at that level", and asserts the result equals root.
+func VerifyInclusion(api frontend.API, root, leaf Digest, path []Digest, indexBits []frontend.Variable) {
+	if len(path) != len(indexBits) {
+		panic("merkle: path and indexBits must have the same length")
+	}
+
+	cur := leaf
+	for i, sibling := range path {
+		left := selectDigest(api, indexBits[i], sibling, cur)
+		right := selectDigest(api, indexBits[i], cur, sibling)
+		cur = Compress2to1(api, left, right)
+	}
+	assertDigestEqual(api, cur, root)
+}
+
+// SortedLeaf is a leaf of a sorted (indexed) Merkle tree: Key is the sorted
+// field the tree is keyed on, Next is the Key of the leaf immediately to
+// its right in sorted order (or a sentinel "infinity" value for the
+// rightmost leaf), and Hash is the digest actually stored in the tree
+// (typically Compress2to1/16to1 applied to Key, Next and any payload).
+type SortedLeaf struct {
+	Key  babybear.Variable
+	Next babybear.Variable
+	Hash Digest
+}
+
+// VerifyNonInclusion proves key falls strictly between two adjacent leaves
+// of a sorted Merkle tree: both leftLeaf and rightLeaf are included under
+// root, leftLeaf.Key < key < rightLeaf.Key, and leftLeaf.Next == rightLeaf.Key
+// so no leaf could sit between them.
+func VerifyNonInclusion(
+	api frontend.API,
+	root Digest,
+	key babybear.Variable,
+	leftLeaf, rightLeaf SortedLeaf,
+	leftPath, rightPath []Digest,
+	leftIndexBits, rightIndexBits []frontend.Variable,
+) {
+	VerifyInclusion(api, root, leftLeaf.Hash, leftPath, leftIndexBits)
+	VerifyInclusion(api, root, rightLeaf.Hash, rightPath, rightIndexBits)
+
+	// api.Cmp compares .Value as a raw BN254 field element, not a BabyBear
+	// one. Without range-checking Key/key into the BabyBear range first, a
+	// prover could supply a non-canonical representative - any BN254 value,
+	// since nothing here forces .Value below the modulus - that happens to
+	// order as "between" the two leaves without being the BabyBear element
+	// the leaf's Hash actually commits to, breaking non-inclusion soundness.
+	// ReduceSlow with the field modulus as UpperBound is the same
+	// canonicalization fieldhasher.go's SetState relies on for the same
+	// reason; leftLeaf.Next doesn't need its own reduction since it's only
+	// ever used in the AssertIsEqual below, against the now-canonical
+	// rightKey.
+	fieldApi := babybear.NewChip(api)
+	leftKey := fieldApi.ReduceSlow(babybear.Variable{Value: leftLeaf.Key.Value, UpperBound: babybearModulus})
+	rightKey := fieldApi.ReduceSlow(babybear.Variable{Value: rightLeaf.Key.Value, UpperBound: babybearModulus})
+	canonicalKey := fieldApi.ReduceSlow(babybear.Variable{Value: key.Value, UpperBound: babybearModulus})
+
+	api.AssertIsEqual(api.Cmp(leftKey.Value, canonicalKey.Value), -1)
+	api.AssertIsEqual(api.Cmp(canonicalKey.Value, rightKey.Value), -1)
+	api.AssertIsEqual(leftLeaf.Next.Value, rightKey.Value)
+}
@@ -0,0 +1,117 @@
+package merkle
+
+import (
+	"github.com/brevis-network/pico/gnark/babybear"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"testing"
+)
+
+func zeroDigest() Digest {
+	var d Digest
+	for i := range d {
+		d[i] = babybear.NewFConst("0")
+	}
+	return d
+}
+
+type inclusionCircuit struct {
+	Leaf    Digest `gnark:",public"`
+	Sibling Digest `gnark:",public"`
+}
+
+func (c *inclusionCircuit) Define(api frontend.API) error {
+	root := Compress2to1(api, c.Leaf, c.Sibling)
+	VerifyInclusion(api, root, c.Leaf, []Digest{c.Sibling}, []frontend.Variable{0})
+	return nil
+}
+
+// TestVerifyInclusionSingleLevel checks that a root recomputed by
+// Compress2to1 on the spot is accepted by VerifyInclusion for the same
+// leaf/sibling pair and index bit, the same shape TestPoseidon2BabyBear
+// uses to exercise the raw permutation.
+func TestVerifyInclusionSingleLevel(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	circuit := &inclusionCircuit{Leaf: zeroDigest(), Sibling: zeroDigest()}
+	witness := &inclusionCircuit{Leaf: zeroDigest(), Sibling: zeroDigest()}
+
+	err := test.IsSolved(circuit, witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+}
+
+type nonInclusionCircuit struct {
+	Key       babybear.Variable `gnark:",public"`
+	LeftLeaf  SortedLeaf        `gnark:",public"`
+	RightLeaf SortedLeaf        `gnark:",public"`
+}
+
+func (c *nonInclusionCircuit) Define(api frontend.API) error {
+	root := Compress2to1(api, c.LeftLeaf.Hash, c.RightLeaf.Hash)
+	VerifyNonInclusion(api, root, c.Key, c.LeftLeaf, c.RightLeaf,
+		[]Digest{c.RightLeaf.Hash}, []Digest{c.LeftLeaf.Hash},
+		[]frontend.Variable{0}, []frontend.Variable{1})
+	return nil
+}
+
+// TestVerifyNonInclusionAcceptsGap checks that VerifyNonInclusion accepts a
+// key strictly between leftLeaf.Key and rightLeaf.Key when leftLeaf.Next ==
+// rightLeaf.Key, using canonical BabyBear Key values - the case
+// VerifyNonInclusion's added ReduceSlow canonicalization must still allow.
+func TestVerifyNonInclusionAcceptsGap(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	leftLeaf := SortedLeaf{
+		Key:  babybear.NewFConst("1"),
+		Next: babybear.NewFConst("3"),
+		Hash: zeroDigest(),
+	}
+	rightLeaf := SortedLeaf{
+		Key:  babybear.NewFConst("3"),
+		Next: babybear.NewFConst("3"),
+		Hash: zeroDigest(),
+	}
+
+	circuit := &nonInclusionCircuit{Key: babybear.NewFConst("2"), LeftLeaf: leftLeaf, RightLeaf: rightLeaf}
+	witness := &nonInclusionCircuit{Key: babybear.NewFConst("2"), LeftLeaf: leftLeaf, RightLeaf: rightLeaf}
+
+	err := test.IsSolved(circuit, witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+}
+
+func fakeNativeCompress(left, right NativeDigest) NativeDigest {
+	var out NativeDigest
+	for i := range out {
+		out[i] = (left[i] + right[i] + uint32(i)) % 2013265921
+	}
+	return out
+}
+
+// TestTreePathRoundTrips checks that every leaf's Path/indexBits recombine
+// through the (fake, additive) compression function back to Root(), the
+// out-of-circuit analogue of TestVerifyInclusionSingleLevel.
+func TestTreePathRoundTrips(t *testing.T) {
+	leaves := make([]NativeDigest, 4)
+	for i := range leaves {
+		leaves[i][0] = uint32(i + 1)
+	}
+
+	tree := NewTree(leaves, fakeNativeCompress)
+	root := tree.Root()
+
+	for i := range leaves {
+		path, indexBits := tree.Path(i)
+		cur := leaves[i]
+		for level, sibling := range path {
+			if indexBits[level] == 1 {
+				cur = fakeNativeCompress(sibling, cur)
+			} else {
+				cur = fakeNativeCompress(cur, sibling)
+			}
+		}
+		if cur != root {
+			t.Fatalf("leaf %d: recomputed root %v != tree root %v", i, cur, root)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package merkle
+
+import "github.com/brevis-network/pico/gnark/poseidon2"
+
+// NativeDigest is the out-of-circuit counterpart of Digest: 16 native
+// BabyBear field elements (reduced mod 2013265921), represented as uint32
+// so a Tree can be built and witnessed without a frontend.API.
+type NativeDigest = [poseidon2.BABYBEAR_WIDTH]uint32
+
+// NativeCompress2to1 is the out-of-circuit analogue of Compress2to1 - a
+// full Poseidon2 permutation over native BabyBear limbs, absorbing left
+// then right the same way Compress2to1 absorbs them in-circuit. Callers
+// wire in whatever native Poseidon2 implementation the rest of the zk-VM
+// already uses (this package only owns the in-circuit gadgets), so the
+// prover below takes it as a parameter instead of re-implementing it.
+type NativeCompress2to1 func(left, right NativeDigest) NativeDigest
+
+// Tree is an out-of-circuit binary Merkle tree over NativeDigest leaves,
+// built bottom-up with a caller-supplied compression function so the prover
+// side can stay in sync with whatever hashes the verifier circuit expects.
+type Tree struct {
+	compress NativeCompress2to1
+	layers   [][]NativeDigest
+}
+
+// NewTree pads leaves up to the next power of two by repeating the last
+// leaf, then builds every layer up to the root.
+func NewTree(leaves []NativeDigest, compress NativeCompress2to1) *Tree {
+	if len(leaves) == 0 {
+		panic("merkle: cannot build a tree with no leaves")
+	}
+
+	padded := append([]NativeDigest(nil), leaves...)
+	for !isPowerOfTwo(len(padded)) {
+		padded = append(padded, padded[len(padded)-1])
+	}
+
+	layers := [][]NativeDigest{padded}
+	for len(layers[len(layers)-1]) > 1 {
+		prev := layers[len(layers)-1]
+		next := make([]NativeDigest, len(prev)/2)
+		for i := range next {
+			next[i] = compress(prev[2*i], prev[2*i+1])
+		}
+		layers = append(layers, next)
+	}
+
+	return &Tree{compress: compress, layers: layers}
+}
+
+func isPowerOfTwo(n int) bool {
+	return n > 0 && n&(n-1) == 0
+}
+
+// Root returns the tree's root digest.
+func (t *Tree) Root() NativeDigest {
+	top := t.layers[len(t.layers)-1]
+	return top[0]
+}
+
+// Path returns the sibling digests and left/right index bits for leaf
+// index, in the same bottom-to-top order VerifyInclusion expects for
+// path/indexBits.
+func (t *Tree) Path(index int) (path []NativeDigest, indexBits []int) {
+	for level := 0; level < len(t.layers)-1; level++ {
+		layer := t.layers[level]
+		siblingIndex := index ^ 1
+		path = append(path, layer[siblingIndex])
+		indexBits = append(indexBits, index&1)
+		index /= 2
+	}
+	return path, indexBits
+}
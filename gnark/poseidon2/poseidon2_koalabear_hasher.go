@@ -0,0 +1,94 @@
+package poseidon2
+
+import (
+	"github.com/brevis-network/brevis-vm/gnark/koalabear"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash"
+	"math/big"
+)
+
+// koalabearLimbBits mirrors babybearLimbBits: comfortably under KoalaBear's
+// 31-bit modulus so every split limb range-checks to a canonical element.
+const koalabearLimbBits = 30
+
+// koalabearModulus is KoalaBear's prime (2^31-2^24+1), used as the
+// UpperBound for SetState's incoming lanes - see babybearModulus's doc
+// comment in fieldhasher.go for why the limb bound isn't sound here.
+var koalabearModulus = big.NewInt(2130706433)
+
+// Poseidon2KoalaBearHasher is the KoalaBear counterpart of
+// Poseidon2BabyBearHasher - see its doc comment for the absorb/squeeze
+// scheme, which is identical modulo the field.
+type Poseidon2KoalaBearHasher struct {
+	api  frontend.API
+	chip *Poseidon2KoalaBearChip
+}
+
+var _ hash.StateStorer = (*Poseidon2KoalaBearHasher)(nil)
+
+func NewKoalaBearHasher(api frontend.API) *Poseidon2KoalaBearHasher {
+	return &Poseidon2KoalaBearHasher{api: api, chip: NewKoalaBearChip(api)}
+}
+
+func (h *Poseidon2KoalaBearHasher) Write(data ...frontend.Variable) {
+	for _, d := range data {
+		for _, limb := range h.splitToLimbs(d) {
+			h.chip.Update(limb)
+		}
+	}
+}
+
+func (h *Poseidon2KoalaBearHasher) Sum() frontend.Variable {
+	state := h.chip.Finalize()
+	return h.packLimbs(state[:numPackedLimbs])
+}
+
+func (h *Poseidon2KoalaBearHasher) Reset() {
+	h.chip = NewKoalaBearChip(h.api)
+}
+
+func (h *Poseidon2KoalaBearHasher) State() []frontend.Variable {
+	state := make([]frontend.Variable, KOALABEAR_WIDTH)
+	for i, lane := range h.chip.State {
+		state[i] = lane.Value
+	}
+	return state
+}
+
+func (h *Poseidon2KoalaBearHasher) SetState(state []frontend.Variable) error {
+	if len(state) != KOALABEAR_WIDTH {
+		return frontend.ErrInputNotSet
+	}
+	for i, v := range state {
+		h.chip.State[i] = h.chip.fieldApi.ReduceSlow(koalabear.Variable{Value: v, UpperBound: koalabearModulus})
+	}
+	return nil
+}
+
+func (h *Poseidon2KoalaBearHasher) splitToLimbs(v frontend.Variable) []koalabear.Variable {
+	bits := h.api.ToBinary(v, bn254Bits)
+	limbBound := new(big.Int).Lsh(big.NewInt(1), koalabearLimbBits)
+
+	limbs := make([]koalabear.Variable, 0, (bn254Bits+koalabearLimbBits-1)/koalabearLimbBits)
+	for i := 0; i < bn254Bits; i += koalabearLimbBits {
+		end := i + koalabearLimbBits
+		if end > bn254Bits {
+			end = bn254Bits
+		}
+		value := h.api.FromBinary(bits[i:end]...)
+		limbs = append(limbs, h.chip.fieldApi.ReduceSlow(koalabear.Variable{Value: value, UpperBound: limbBound}))
+	}
+	return limbs
+}
+
+func (h *Poseidon2KoalaBearHasher) packLimbs(limbs []koalabear.Variable) frontend.Variable {
+	shift := big.NewInt(1)
+	limbShift := new(big.Int).Lsh(big.NewInt(1), koalabearLimbBits)
+
+	packed := frontend.Variable(0)
+	for _, limb := range limbs {
+		packed = h.api.Add(packed, h.api.Mul(limb.Value, shift))
+		shift = new(big.Int).Mul(shift, limbShift)
+	}
+	return h.api.Add(packed, shift)
+}
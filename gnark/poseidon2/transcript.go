@@ -0,0 +1,70 @@
+package poseidon2
+
+import (
+	"fmt"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash"
+)
+
+// Poseidon2Transcript mirrors std/fiat-shamir's Transcript API (Bind /
+// ComputeChallenge) but drives the challenge oracle with a Poseidon2
+// FieldHasher instead of MiMC or Keccak, so recursive PLONK/Groth16
+// verifier circuits can use the same hash for both the in-circuit sponge
+// and the out-of-circuit challenge derivation.
+type Poseidon2Transcript struct {
+	hasher            hash.StateStorer
+	bindings          map[string][]frontend.Variable
+	challenges        map[string]frontend.Variable
+	previousChallenge frontend.Variable
+}
+
+// NewTranscript builds a transcript over the given hasher (typically
+// NewBabyBearHasher/NewKoalaBearHasher), pre-registering the challenge IDs
+// the caller intends to compute.
+func NewTranscript(hasher hash.StateStorer, challengesID ...string) *Poseidon2Transcript {
+	t := &Poseidon2Transcript{
+		hasher:     hasher,
+		bindings:   make(map[string][]frontend.Variable, len(challengesID)),
+		challenges: make(map[string]frontend.Variable, len(challengesID)),
+	}
+	for _, id := range challengesID {
+		t.bindings[id] = nil
+	}
+	return t
+}
+
+// Bind appends bindingsToAdd to the list of frontend.Variables that will be
+// absorbed the next time challengeID's challenge is computed.
+func (t *Poseidon2Transcript) Bind(challengeID string, bindingsToAdd []frontend.Variable) error {
+	if _, ok := t.bindings[challengeID]; !ok {
+		return fmt.Errorf("unknown challenge id: %s", challengeID)
+	}
+	if _, ok := t.challenges[challengeID]; ok {
+		return fmt.Errorf("challenge %s already computed", challengeID)
+	}
+	t.bindings[challengeID] = append(t.bindings[challengeID], bindingsToAdd...)
+	return nil
+}
+
+// ComputeChallenge absorbs the previous challenge (if any) together with
+// everything bound to challengeID, squeezes the Poseidon2 sponge, and
+// caches + returns the result.
+func (t *Poseidon2Transcript) ComputeChallenge(challengeID string) (frontend.Variable, error) {
+	if challenge, ok := t.challenges[challengeID]; ok {
+		return challenge, nil
+	}
+	if _, ok := t.bindings[challengeID]; !ok {
+		return nil, fmt.Errorf("unknown challenge id: %s", challengeID)
+	}
+
+	t.hasher.Reset()
+	if t.previousChallenge != nil {
+		t.hasher.Write(t.previousChallenge)
+	}
+	t.hasher.Write(t.bindings[challengeID]...)
+
+	challenge := t.hasher.Sum()
+	t.challenges[challengeID] = challenge
+	t.previousChallenge = challenge
+	return challenge, nil
+}
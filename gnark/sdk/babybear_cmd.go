@@ -17,6 +17,10 @@ import (
 )
 
 func BabyBearCmd(cmd string) (err error) {
+	if os.Getenv("BACKEND") == "plonk" {
+		return babyBearPlonkCmd(cmd)
+	}
+
 	switch cmd {
 	case "prove":
 		err = BabyBearProve()
@@ -86,7 +90,20 @@ func DoBabyBearSolve() (circuit *babybear_verifier.Circuit, assigment *babybear_
 	return circuit, assigment, nil
 }
 
+// BabyBearSetup compiles/sets up/proves the BabyBear verifier circuit
+// directly over BN254, the default (and only non-wrapped) outer curve. Any
+// other OUTER_CURVE dispatches to setupWrapped instead, which recursively
+// wraps a BLS12-377 inner proof over BW6-761 and returns an error for the
+// other outer curves.
 func BabyBearSetup() error {
+	outerCurve, err := ParseOuterCurve(os.Getenv("OUTER_CURVE"))
+	if err != nil {
+		return fmt.Errorf("fail to parse outer curve: %v", err)
+	}
+	if outerCurve != ecc.BN254 {
+		return setupWrapped(outerCurve)
+	}
+
 	circuit, assigment, err := DoBabyBearSolve()
 	if err != nil {
 		return fmt.Errorf("fail to solve: %v\n", err)
@@ -135,7 +152,18 @@ func BabyBearSetup() error {
 	return nil
 }
 
+// BabyBearProve proves against a BN254 Pk/Vk already on disk, the default
+// (and only non-wrapped) outer curve. Any other OUTER_CURVE dispatches to
+// proveWrapped instead.
 func BabyBearProve() error {
+	outerCurve, err := ParseOuterCurve(os.Getenv("OUTER_CURVE"))
+	if err != nil {
+		return fmt.Errorf("fail to parse outer curve: %v", err)
+	}
+	if outerCurve != ecc.BN254 {
+		return proveWrapped(outerCurve)
+	}
+
 	loadLock.Add(2) // 1 for load pk, 1 for compile ccs
 
 	var reafProveKeyErr, compileCcsErr error
@@ -144,7 +172,7 @@ func BabyBearProve() error {
 		reafProveKeyErr = utils.ReadProvingKey(os.Getenv("PK_PATH"), Pk)
 	}()
 
-	err := utils.ReadVerifyingKey(os.Getenv("VK_PATH"), Vk)
+	err = utils.ReadVerifyingKey(os.Getenv("VK_PATH"), Vk)
 	if err != nil {
 		return fmt.Errorf("failed to read verifing key: %v", err)
 	}
@@ -0,0 +1,310 @@
+package sdk
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/brevis-network/pico/gnark/utils"
+	"github.com/consensys/gnark-crypto/ecc"
+	bls12377_fr "github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/std/algebra/native/sw_bls12377"
+	recursion_groth16 "github.com/consensys/gnark/std/recursion/groth16"
+	"golang.org/x/crypto/sha3"
+)
+
+// innerCurveFor returns the 2-chain inner curve whose Groth16 proofs
+// WrapperCircuit can verify in-circuit over outer. Only the BLS12-377/
+// BW6-761 pair is wired today; ParseOuterCurve accepts bls12-377 and
+// bls24-315 too, but there's no WrapperCircuit instantiation for them yet.
+func innerCurveFor(outer ecc.ID) (ecc.ID, error) {
+	if outer == ecc.BW6_761 {
+		return ecc.BLS12_377, nil
+	}
+	return ecc.UNKNOWN, fmt.Errorf("no wrapper circuit wired for outer curve %s yet", outer)
+}
+
+// innerKeyPaths derives where the inner (BLS12-377) Pk/Vk get persisted,
+// alongside the outer Pk/Vk at PK_PATH/VK_PATH. Persisting them is required,
+// not optional: WrapperCircuit.VerifyingKey is `gnark:"-"`, a compile-time
+// circuit constant, so the outer ccs compiled at setup time and the one
+// recompiled at prove time must be built against the exact same inner vk or
+// they're different circuits and the proof won't verify.
+func innerKeyPaths() (pkPath, vkPath string) {
+	return os.Getenv("PK_PATH") + ".inner", os.Getenv("VK_PATH") + ".inner"
+}
+
+// setupWrapped is BabyBearSetup's recursive counterpart: it compiles/sets
+// up/proves the BabyBear verifier circuit over outerCurve's inner curve
+// (BLS12-377), wraps that proof in a WrapperCircuit, and compiles/sets up/
+// proves the wrapper over outerCurve itself (BW6-761). No Solidity verifier
+// is exported for the BW6-761 keys (ExportSolidify accepts BN254 only).
+// Pk/Vk at PK_PATH/VK_PATH end up being the
+// outer (wrapper) keys; the inner Pk/Vk also get persisted, at
+// innerKeyPaths(), since proveWrapped must reuse the exact inner vk baked
+// into the outer ccs here rather than generate its own.
+func setupWrapped(outerCurve ecc.ID) error {
+	innerCurve, err := innerCurveFor(outerCurve)
+	if err != nil {
+		return err
+	}
+
+	circuit, assignment, err := DoBabyBearSolve()
+	if err != nil {
+		return fmt.Errorf("fail to solve: %v", err)
+	}
+
+	innerField := innerCurve.ScalarField()
+	innerFullWitness, err := frontend.NewWitness(assignment, innerField)
+	if err != nil {
+		return fmt.Errorf("fail to gen inner full witness: %v", err)
+	}
+	innerPubWitness, err := innerFullWitness.Public()
+	if err != nil {
+		return fmt.Errorf("fail to gen inner public witness: %v", err)
+	}
+
+	innerCcs, err := frontend.Compile(innerField, r1cs.NewBuilder, circuit)
+	if err != nil {
+		return fmt.Errorf("fail to compile inner circuit over %s: %v", innerCurve, err)
+	}
+	inner, err := OuterBackend(innerCurve)
+	if err != nil {
+		return err
+	}
+	inner.Ccs = innerCcs
+	fmt.Printf("inner ccs (%s): %d \n", innerCurve, innerCcs.GetNbConstraints())
+
+	inner.Pk, inner.Vk, err = groth16.Setup(innerCcs)
+	if err != nil {
+		return fmt.Errorf("fail to setup inner groth16: %v", err)
+	}
+
+	innerPkPath, innerVkPath := innerKeyPaths()
+	if err := utils.WriteProvingKey(innerPkPath, inner.Pk); err != nil {
+		return fmt.Errorf("fail to write inner pk: %v", err)
+	}
+	if err := utils.WriteVerifyingKey(innerVkPath, inner.Vk); err != nil {
+		return fmt.Errorf("fail to write inner vk: %v", err)
+	}
+
+	innerProof, err := groth16.Prove(innerCcs, inner.Pk, innerFullWitness, backend.WithProverHashToFieldFunction(sha3.NewLegacyKeccak256()))
+	if err != nil {
+		return fmt.Errorf("fail to prove inner groth16: %v", err)
+	}
+	err = groth16.Verify(innerProof, inner.Vk, innerPubWitness, backend.WithVerifierHashToFieldFunction(sha3.NewLegacyKeccak256()))
+	if err != nil {
+		return fmt.Errorf("fail to verify inner proof: %v", err)
+	}
+
+	innerPubVars, err := publicWitnessVariables(innerPubWitness)
+	if err != nil {
+		return fmt.Errorf("fail to convert inner public witness: %v", err)
+	}
+
+	wrapperAssignment, err := Wrap(innerProof, inner.Vk, innerPubVars)
+	if err != nil {
+		return fmt.Errorf("fail to wrap inner proof: %v", err)
+	}
+
+	// WrapperCircuit.VerifyingKey is `gnark:"-"`, a Go-level constant baked
+	// into the constraint system at compile time rather than a witness
+	// field, so it must be compiled with the real inner.Vk (just persisted
+	// above), not a zero-valued placeholder — a placeholder vk here would
+	// bake in a meaningless constant and AssertProof could never match a
+	// genuine inner proof against it.
+	wrapperForCompile := &WrapperCircuit{
+		Proof:        recursion_groth16.PlaceholderProof[sw_bls12377.G1Affine, sw_bls12377.G2Affine](innerCcs),
+		VerifyingKey: wrapperAssignment.VerifyingKey,
+		Witness:      recursion_groth16.PlaceholderWitness[sw_bls12377.Fr](innerCcs),
+	}
+
+	outerField := outerCurve.ScalarField()
+	outerCcs, err := frontend.Compile(outerField, r1cs.NewBuilder, wrapperForCompile)
+	if err != nil {
+		return fmt.Errorf("fail to compile wrapper circuit over %s: %v", outerCurve, err)
+	}
+	outer, err := OuterBackend(outerCurve)
+	if err != nil {
+		return err
+	}
+	outer.Ccs = outerCcs
+	fmt.Printf("outer ccs (%s): %d \n", outerCurve, outerCcs.GetNbConstraints())
+
+	outer.Pk, outer.Vk, err = groth16.Setup(outerCcs)
+	if err != nil {
+		return fmt.Errorf("fail to setup outer groth16: %v", err)
+	}
+
+	outerFullWitness, err := frontend.NewWitness(wrapperAssignment, outerField)
+	if err != nil {
+		return fmt.Errorf("fail to gen outer full witness: %v", err)
+	}
+	outerPubWitness, err := outerFullWitness.Public()
+	if err != nil {
+		return fmt.Errorf("fail to gen outer public witness: %v", err)
+	}
+
+	outerProof, err := groth16.Prove(outerCcs, outer.Pk, outerFullWitness)
+	if err != nil {
+		return fmt.Errorf("fail to prove outer groth16: %v", err)
+	}
+	err = groth16.Verify(outerProof, outer.Vk, outerPubWitness)
+	if err != nil {
+		return fmt.Errorf("fail to verify outer proof: %v", err)
+	}
+
+	err = utils.WriteProvingKey(os.Getenv("PK_PATH"), outer.Pk)
+	if err != nil {
+		return fmt.Errorf("fail to write pk: %v", err)
+	}
+	err = utils.WriteVerifyingKey(os.Getenv("VK_PATH"), outer.Vk)
+	if err != nil {
+		return fmt.Errorf("fail to write vk: %v", err)
+	}
+	return nil
+}
+
+// proveWrapped is BabyBearProve's recursive counterpart: it re-solves the
+// circuit, re-runs the inner BLS12-377 proof against the inner Pk/Vk
+// setupWrapped persisted at innerKeyPaths(), wraps it, and proves the
+// wrapper circuit (recompiled against that same inner vk) against the outer
+// Pk/Vk loaded from PK_PATH/VK_PATH.
+func proveWrapped(outerCurve ecc.ID) error {
+	innerCurve, err := innerCurveFor(outerCurve)
+	if err != nil {
+		return err
+	}
+
+	outer, err := OuterBackend(outerCurve)
+	if err != nil {
+		return err
+	}
+	outer.Pk = groth16.NewProvingKey(outerCurve)
+	outer.Vk = groth16.NewVerifyingKey(outerCurve)
+	if err := utils.ReadProvingKey(os.Getenv("PK_PATH"), outer.Pk); err != nil {
+		return fmt.Errorf("failed to read outer proving key: %v", err)
+	}
+	if err := utils.ReadVerifyingKey(os.Getenv("VK_PATH"), outer.Vk); err != nil {
+		return fmt.Errorf("failed to read outer verifying key: %v", err)
+	}
+
+	circuit, assignment, err := DoBabyBearSolve()
+	if err != nil {
+		return fmt.Errorf("fail to solve: %v", err)
+	}
+
+	innerField := innerCurve.ScalarField()
+	innerFullWitness, err := frontend.NewWitness(assignment, innerField)
+	if err != nil {
+		return fmt.Errorf("failed to get inner full witness: %v", err)
+	}
+	innerPubWitness, err := innerFullWitness.Public()
+	if err != nil {
+		return fmt.Errorf("failed to get inner public witness: %v", err)
+	}
+
+	innerCcs, err := frontend.Compile(innerField, r1cs.NewBuilder, circuit)
+	if err != nil {
+		return fmt.Errorf("fail to compile inner circuit over %s: %v", innerCurve, err)
+	}
+	inner, err := OuterBackend(innerCurve)
+	if err != nil {
+		return err
+	}
+	inner.Ccs = innerCcs
+
+	// The inner Pk/Vk must be the exact ones setupWrapped baked into the
+	// outer ccs as a compile-time constant (see innerKeyPaths), not a fresh
+	// groth16.Setup: a new setup produces an unrelated random inner vk that
+	// the already-compiled/setup outer circuit was never built against.
+	innerPkPath, innerVkPath := innerKeyPaths()
+	inner.Pk = groth16.NewProvingKey(innerCurve)
+	inner.Vk = groth16.NewVerifyingKey(innerCurve)
+	if err := utils.ReadProvingKey(innerPkPath, inner.Pk); err != nil {
+		return fmt.Errorf("failed to read inner proving key: %v", err)
+	}
+	if err := utils.ReadVerifyingKey(innerVkPath, inner.Vk); err != nil {
+		return fmt.Errorf("failed to read inner verifying key: %v", err)
+	}
+
+	innerProof, err := groth16.Prove(innerCcs, inner.Pk, innerFullWitness, backend.WithProverHashToFieldFunction(sha3.NewLegacyKeccak256()))
+	if err != nil {
+		return fmt.Errorf("failed to prove inner: %v", err)
+	}
+
+	innerPubVars, err := publicWitnessVariables(innerPubWitness)
+	if err != nil {
+		return fmt.Errorf("fail to convert inner public witness: %v", err)
+	}
+	wrapperAssignment, err := Wrap(innerProof, inner.Vk, innerPubVars)
+	if err != nil {
+		return fmt.Errorf("fail to wrap inner proof: %v", err)
+	}
+
+	outerField := outerCurve.ScalarField()
+	outerFullWitness, err := frontend.NewWitness(wrapperAssignment, outerField)
+	if err != nil {
+		return fmt.Errorf("failed to get outer full witness: %v", err)
+	}
+	outerPubWitness, err := outerFullWitness.Public()
+	if err != nil {
+		return fmt.Errorf("failed to get outer public witness: %v", err)
+	}
+
+	// Recompiled against the same inner.Vk just read back from disk, so this
+	// is bit-for-bit the same ccs setupWrapped compiled and setup Pk/Vk for.
+	outerCcs, err := frontend.Compile(outerField, r1cs.NewBuilder, &WrapperCircuit{
+		Proof:        recursion_groth16.PlaceholderProof[sw_bls12377.G1Affine, sw_bls12377.G2Affine](innerCcs),
+		VerifyingKey: wrapperAssignment.VerifyingKey,
+		Witness:      recursion_groth16.PlaceholderWitness[sw_bls12377.Fr](innerCcs),
+	})
+	if err != nil {
+		return fmt.Errorf("fail to compile wrapper circuit over %s: %v", outerCurve, err)
+	}
+	outer.Ccs = outerCcs
+
+	outerProof, err := groth16.Prove(outerCcs, outer.Pk, outerFullWitness)
+	if err != nil {
+		return fmt.Errorf("failed to prove outer: %v", err)
+	}
+	err = groth16.Verify(outerProof, outer.Vk, outerPubWitness)
+	if err != nil {
+		return fmt.Errorf("failed to verify outer proof: %v", err)
+	}
+
+	// No comma-hex on-chain bundle encoder exists yet for outer curves
+	// other than BN254 (utils.GetAggOnChainProof/BLS12381 are both tied to
+	// a specific curve's proof type); write the raw serialized proof so
+	// callers can still consume it until one is added.
+	f, err := os.Create(os.Getenv("PROOF_PATH"))
+	if err != nil {
+		return fmt.Errorf("failed to create proof file: %v", err)
+	}
+	defer f.Close()
+	if _, err := outerProof.WriteTo(f); err != nil {
+		return fmt.Errorf("failed to write proof: %v", err)
+	}
+	fmt.Println("proof written successfully")
+	return nil
+}
+
+// publicWitnessVariables extracts a BLS12-377 public witness's field
+// elements as frontend.Variables, the shape Wrap's
+// recursion_groth16.ValueOfWitness call expects.
+func publicWitnessVariables(pubWitness witness.Witness) ([]frontend.Variable, error) {
+	vec, ok := pubWitness.Vector().(bls12377_fr.Vector)
+	if !ok {
+		return nil, fmt.Errorf("public witness is not a bls12-377 vector")
+	}
+	vars := make([]frontend.Variable, len(vec))
+	for i := range vec {
+		vars[i] = vec[i].BigInt(new(big.Int))
+	}
+	return vars, nil
+}
@@ -0,0 +1,116 @@
+package sdk
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/std/algebra/native/sw_bls12377"
+	recursion_groth16 "github.com/consensys/gnark/std/recursion/groth16"
+	"github.com/consensys/gnark/test"
+	"golang.org/x/crypto/sha3"
+)
+
+// squareCircuit is a stand-in for the real BabyBear verifier circuit, which
+// isn't available in this tree (babybear_verifier requires a WITNESS_JSON
+// fixture that doesn't exist here). It exercises the same recursion shape
+// setupWrapped/proveWrapped rely on: an inner BLS12-377 Groth16 proof
+// wrapped and verified in a WrapperCircuit over BW6-761.
+type squareCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *squareCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(c.X, c.X), c.Y)
+	return nil
+}
+
+// TestWrapEndToEnd is the end-to-end setup->prove->verify test the review
+// asked for: it proves squareCircuit over the inner curve, wraps that proof
+// with the *concrete* inner vk (not a placeholder) the way setupWrapped now
+// does, and checks the resulting outer proof verifies. It then re-derives a
+// second wrapper proof reusing that same inner vk, mirroring how
+// proveWrapped must reuse setupWrapped's persisted inner vk rather than
+// generate its own - this is the regression the review flagged.
+func TestWrapEndToEnd(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	innerField := ecc.BLS12_377.ScalarField()
+	innerCcs, err := frontend.Compile(innerField, r1cs.NewBuilder, &squareCircuit{})
+	assert.NoError(err)
+
+	innerPk, innerVk, err := groth16.Setup(innerCcs)
+	assert.NoError(err)
+
+	assignment := &squareCircuit{X: 3, Y: 9}
+	innerFullWitness, err := frontend.NewWitness(assignment, innerField)
+	assert.NoError(err)
+	innerPubWitness, err := innerFullWitness.Public()
+	assert.NoError(err)
+
+	innerProof, err := groth16.Prove(innerCcs, innerPk, innerFullWitness, backend.WithProverHashToFieldFunction(sha3.NewLegacyKeccak256()))
+	assert.NoError(err)
+	err = groth16.Verify(innerProof, innerVk, innerPubWitness, backend.WithVerifierHashToFieldFunction(sha3.NewLegacyKeccak256()))
+	assert.NoError(err)
+
+	innerPubVars, err := publicWitnessVariables(innerPubWitness)
+	assert.NoError(err)
+
+	wrapperAssignment, err := Wrap(innerProof, innerVk, innerPubVars)
+	assert.NoError(err)
+
+	// Compile with the concrete inner vk, not PlaceholderVerifyingKey: it's
+	// baked in as a `gnark:"-"` compile-time constant, so a placeholder here
+	// would never match a real inner proof.
+	outerField := ecc.BW6_761.ScalarField()
+	outerCcs, err := frontend.Compile(outerField, r1cs.NewBuilder, &WrapperCircuit{
+		Proof:        recursion_groth16.PlaceholderProof[sw_bls12377.G1Affine, sw_bls12377.G2Affine](innerCcs),
+		VerifyingKey: wrapperAssignment.VerifyingKey,
+		Witness:      recursion_groth16.PlaceholderWitness[sw_bls12377.Fr](innerCcs),
+	})
+	assert.NoError(err)
+
+	outerPk, outerVk, err := groth16.Setup(outerCcs)
+	assert.NoError(err)
+
+	outerFullWitness, err := frontend.NewWitness(wrapperAssignment, outerField)
+	assert.NoError(err)
+	outerPubWitness, err := outerFullWitness.Public()
+	assert.NoError(err)
+
+	outerProof, err := groth16.Prove(outerCcs, outerPk, outerFullWitness)
+	assert.NoError(err)
+	err = groth16.Verify(outerProof, outerVk, outerPubWitness)
+	assert.NoError(err)
+
+	// Second round: reuse the same inner vk (as proveWrapped now does via
+	// innerKeyPaths) to prove a fresh inner witness, recompile the outer ccs
+	// against that same vk, and confirm it still verifies against outerVk.
+	assignment2 := &squareCircuit{X: 5, Y: 25}
+	innerFullWitness2, err := frontend.NewWitness(assignment2, innerField)
+	assert.NoError(err)
+	innerPubWitness2, err := innerFullWitness2.Public()
+	assert.NoError(err)
+
+	innerProof2, err := groth16.Prove(innerCcs, innerPk, innerFullWitness2, backend.WithProverHashToFieldFunction(sha3.NewLegacyKeccak256()))
+	assert.NoError(err)
+
+	innerPubVars2, err := publicWitnessVariables(innerPubWitness2)
+	assert.NoError(err)
+	wrapperAssignment2, err := Wrap(innerProof2, innerVk, innerPubVars2)
+	assert.NoError(err)
+
+	outerFullWitness2, err := frontend.NewWitness(wrapperAssignment2, outerField)
+	assert.NoError(err)
+	outerPubWitness2, err := outerFullWitness2.Public()
+	assert.NoError(err)
+
+	outerProof2, err := groth16.Prove(outerCcs, outerPk, outerFullWitness2)
+	assert.NoError(err)
+	err = groth16.Verify(outerProof2, outerVk, outerPubWitness2)
+	assert.NoError(err)
+}
@@ -0,0 +1,74 @@
+package sdk
+
+import (
+	"fmt"
+	"github.com/brevis-network/pico/gnark/utils"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/backend/witness"
+	"os"
+)
+
+// ProofSystem selects which proving system ReadProvingKey, ReadVerifyingKey,
+// ExportSolidity and ProveWithBackend dispatch to. They cover key loading,
+// proving and Solidity export only: compiling the circuit differs between
+// the two (r1cs for Groth16 in BabyBearSetup/BabyBearProve, scs for PLONK in
+// PlonkSetup/PlonkProve) and is not done here.
+type ProofSystem string
+
+const (
+	Groth16 ProofSystem = "groth16"
+	Plonk   ProofSystem = "plonk"
+)
+
+// ParseProofSystem maps the -backend flag value to a ProofSystem,
+// defaulting an empty string to Groth16 so existing setups keep working.
+func ParseProofSystem(name string) (ProofSystem, error) {
+	switch name {
+	case "", "groth16":
+		return Groth16, nil
+	case "plonk":
+		return Plonk, nil
+	default:
+		return "", fmt.Errorf("unsupported backend: %s", name)
+	}
+}
+
+// ReadProvingKey loads the proving key at PK_PATH into Pk or PlonkPk,
+// depending on system.
+func ReadProvingKey(system ProofSystem) error {
+	if system == Plonk {
+		PlonkPk = plonk.NewProvingKey(ecc.BN254)
+		return utils.ReadPlonkProvingKey(os.Getenv("PK_PATH"), PlonkPk)
+	}
+	return utils.ReadProvingKey(os.Getenv("PK_PATH"), Pk)
+}
+
+// ReadVerifyingKey loads the verifying key at VK_PATH into Vk or PlonkVk,
+// depending on system.
+func ReadVerifyingKey(system ProofSystem) error {
+	if system == Plonk {
+		PlonkVk = plonk.NewVerifyingKey(ecc.BN254)
+		return utils.ReadPlonkVerifyingKey(os.Getenv("VK_PATH"), PlonkVk)
+	}
+	return utils.ReadVerifyingKey(os.Getenv("VK_PATH"), Vk)
+}
+
+// ExportSolidity writes the Solidity verifier for the verifying key at
+// VK_PATH to SOLIDITY_PATH, depending on system.
+func ExportSolidity(system ProofSystem) error {
+	if system == Plonk {
+		return ExportPlonkSolidity()
+	}
+	return ExportSolidify()
+}
+
+// ProveWithBackend re-proves fullWitness/pubWitness against Pk/Ccs or
+// PlonkPk/PlonkCcs, depending on system, and writes the resulting on-chain
+// proof bundle to PROOF_PATH.
+func ProveWithBackend(system ProofSystem, fullWitness, pubWitness witness.Witness) error {
+	if system == Plonk {
+		return provePlonk(fullWitness, pubWitness)
+	}
+	return Prove(fullWitness, pubWitness)
+}
@@ -29,20 +29,34 @@ type PicoGroth16Proof struct {
 	Proof                 string // hex
 }
 
+// ExportSolidify writes the Solidity verifier for the verifying key at
+// VK_PATH, for whichever curve OUTER_CURVE names. gnark's groth16 only
+// implements ExportSolidity for BN254; a wrapped proof over BLS12-377,
+// BW6-761 or BLS24-315 has no Solidity verifier to export, so that's
+// rejected here up front instead of failing deep inside vk.ExportSolidity
+// with a less obvious error. BLS12-381 is not an OUTER_CURVE value, so no
+// Solidity verifier is exported for BLS12-381 keys either.
 func ExportSolidify() error {
-	err := utils.ReadVerifyingKey(os.Getenv("VK_PATH"), Vk)
+	outerCurve, err := ParseOuterCurve(os.Getenv("OUTER_CURVE"))
 	if err != nil {
+		return fmt.Errorf("fail to parse outer curve: %v", err)
+	}
+	if outerCurve != ecc.BN254 {
+		return fmt.Errorf("exportSolidity is not supported for outer curve %s: gnark only implements Solidity export for BN254 verifying keys", outerCurve)
+	}
+
+	vk := groth16.NewVerifyingKey(outerCurve)
+	if err := utils.ReadVerifyingKey(os.Getenv("VK_PATH"), vk); err != nil {
 		return fmt.Errorf("failed to read verifiing key: %v", err)
 	}
 
 	f, err := os.Create(os.Getenv("SOLIDITY_PATH"))
-	defer f.Close()
 	if err != nil {
 		return fmt.Errorf("fail to solidify file: %v", err)
 	}
+	defer f.Close()
 
-	err = Vk.ExportSolidity(f)
-	if err != nil {
+	if err := vk.ExportSolidity(f); err != nil {
 		return fmt.Errorf("fail to export solidity: %v", err)
 	}
 	return nil
@@ -12,11 +12,13 @@ var (
 	pkPath          = flag.String("pk", "./data/vm_pk", "path of proving key")
 	vkPath          = flag.String("vk", "./data/vm_vk", "path of verifying key")
 	useGroth16      = flag.Bool("groth16", true, "use groth16")
+	backend         = flag.String("backend", "groth16", "proving backend to use: groth16|plonk")
 	witnessFile     = flag.String("witness", "./data/groth16_witness.json", "path of witness json file")
 	constraintsFile = flag.String("constraints", "./data/constraints.json", "path of constraint json file")
 	proofPath       = flag.String("proof", "./data/proof.data", "path of proof file")
 	solidifyPath    = flag.String("sol", "./data/pico_vm_verifier.sol", "path of solidify file")
 	field           = flag.String("field", "babybear", "field for proving, support babybear and koala bear")
+	outerCurve      = flag.String("outerCurve", "bn254", "outer curve for the verifier circuit: bn254|bls12-377|bw6-761|bls24-315")
 )
 
 func main() {
@@ -28,7 +30,23 @@ func main() {
 			return
 		}
 	}
-	err := os.Setenv("PK_PATH", *pkPath)
+	err := os.Setenv("BACKEND", *backend)
+	if err != nil {
+		fmt.Printf("failed to set backend env var: %v\n", err)
+		return
+	}
+
+	if _, err = sdk.ParseOuterCurve(*outerCurve); err != nil {
+		fmt.Printf("invalid outer curve: %v\n", err)
+		return
+	}
+	err = os.Setenv("OUTER_CURVE", *outerCurve)
+	if err != nil {
+		fmt.Printf("failed to set outer curve env var: %v\n", err)
+		return
+	}
+
+	err = os.Setenv("PK_PATH", *pkPath)
 	if err != nil {
 		fmt.Printf("failed to set pk env var: %v\n", err)
 		return
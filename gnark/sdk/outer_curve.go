@@ -0,0 +1,108 @@
+package sdk
+
+import (
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/native/sw_bls12377"
+	recursion_groth16 "github.com/consensys/gnark/std/recursion/groth16"
+)
+
+// OuterBackendEntry bundles the Groth16 artifacts needed to setup/prove/
+// export a circuit for one outer curve; Ccs/Pk/Vk are gnark's curve-agnostic
+// interfaces, so one entry per curve (held in outerBackends below) is enough
+// to cover BN254, BLS12-377, BW6-761 and BLS24-315 without a type parameter.
+// It's exported (unlike the map itself) since OuterBackend hands callers one
+// of these.
+type OuterBackendEntry struct {
+	Curve ecc.ID
+	Ccs   constraint.ConstraintSystem
+	Pk    groth16.ProvingKey
+	Vk    groth16.VerifyingKey
+}
+
+var outerBackends = map[ecc.ID]*OuterBackendEntry{
+	ecc.BN254:     {Curve: ecc.BN254, Pk: groth16.NewProvingKey(ecc.BN254), Vk: groth16.NewVerifyingKey(ecc.BN254)},
+	ecc.BLS12_377: {Curve: ecc.BLS12_377, Pk: groth16.NewProvingKey(ecc.BLS12_377), Vk: groth16.NewVerifyingKey(ecc.BLS12_377)},
+	ecc.BW6_761:   {Curve: ecc.BW6_761, Pk: groth16.NewProvingKey(ecc.BW6_761), Vk: groth16.NewVerifyingKey(ecc.BW6_761)},
+	ecc.BLS24_315: {Curve: ecc.BLS24_315, Pk: groth16.NewProvingKey(ecc.BLS24_315), Vk: groth16.NewVerifyingKey(ecc.BLS24_315)},
+}
+
+// ParseOuterCurve maps the -outerCurve flag value to an ecc.ID, defaulting
+// callers to BN254 on an empty string so existing setups keep working.
+func ParseOuterCurve(name string) (ecc.ID, error) {
+	switch name {
+	case "", "bn254":
+		return ecc.BN254, nil
+	case "bls12-377", "bls12_377":
+		return ecc.BLS12_377, nil
+	case "bw6-761", "bw6_761":
+		return ecc.BW6_761, nil
+	case "bls24-315", "bls24_315":
+		return ecc.BLS24_315, nil
+	default:
+		return ecc.UNKNOWN, fmt.Errorf("unsupported outer curve: %s", name)
+	}
+}
+
+// OuterBackend returns the backend for curve, failing if curve isn't one of
+// the four registered in outerBackends.
+func OuterBackend(curve ecc.ID) (*OuterBackendEntry, error) {
+	b, ok := outerBackends[curve]
+	if !ok {
+		return nil, fmt.Errorf("no backend registered for curve %s", curve)
+	}
+	return b, nil
+}
+
+// WrapperCircuit is a gnark circuit that checks a Groth16 proof produced on
+// the inner curve (BLS12-377) inside a circuit compiled over the matching
+// outer curve of a standard 2-chain (BLS12-377 -> BW6-761). gnark's
+// recursion verifier is generic over the inner curve's group types, which
+// must be fixed at compile time, so Wrap picks the concrete instantiation
+// and returns a circuit ready to be compiled/setup/proved like any other.
+// VerifyingKey is `gnark:"-"`: it's baked into the compiled circuit as a
+// constant (one WrapperCircuit per inner Vk), while Proof/Witness vary per
+// call and so are ordinary (private/public) witness fields.
+type WrapperCircuit struct {
+	Proof        recursion_groth16.Proof[sw_bls12377.G1Affine, sw_bls12377.G2Affine]
+	VerifyingKey recursion_groth16.VerifyingKey[sw_bls12377.G1Affine, sw_bls12377.G2Affine, sw_bls12377.GTEl] `gnark:"-"`
+	Witness      recursion_groth16.Witness[sw_bls12377.Fr]                                                    `gnark:",public"`
+}
+
+func (c *WrapperCircuit) Define(api frontend.API) error {
+	verifier, err := recursion_groth16.NewVerifier[sw_bls12377.Fr, sw_bls12377.G1Affine, sw_bls12377.G2Affine, sw_bls12377.GTEl](api)
+	if err != nil {
+		return fmt.Errorf("new recursion verifier: %w", err)
+	}
+	return verifier.AssertProof(c.VerifyingKey, c.Proof, c.Witness)
+}
+
+// Wrap builds a WrapperCircuit assignment (the actual Proof/VerifyingKey/
+// Witness values, not the compiled circuit) for innerProof/innerVk so the
+// caller can frontend.NewWitness it against a WrapperCircuit already
+// compiled/setup over BW6-761, giving recursive proof composition. See
+// setupWrapped/proveWrapped for the full
+// compile/setup/prove flow this feeds into.
+func Wrap(innerProof groth16.Proof, innerVk groth16.VerifyingKey, publicWitness []frontend.Variable) (*WrapperCircuit, error) {
+	proof, err := recursion_groth16.ValueOfProof[sw_bls12377.G1Affine, sw_bls12377.G2Affine](innerProof)
+	if err != nil {
+		return nil, fmt.Errorf("value of inner proof: %w", err)
+	}
+	vk, err := recursion_groth16.ValueOfVerifyingKey[sw_bls12377.G1Affine, sw_bls12377.G2Affine, sw_bls12377.GTEl](innerVk)
+	if err != nil {
+		return nil, fmt.Errorf("value of inner verifying key: %w", err)
+	}
+	witness, err := recursion_groth16.ValueOfWitness[sw_bls12377.Fr](publicWitness)
+	if err != nil {
+		return nil, fmt.Errorf("value of inner witness: %w", err)
+	}
+
+	return &WrapperCircuit{
+		Proof:        proof,
+		VerifyingKey: vk,
+		Witness:      witness,
+	}, nil
+}
@@ -0,0 +1,238 @@
+package sdk
+
+import (
+	"fmt"
+	"github.com/brevis-network/pico/gnark/utils"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/backend/witness"
+	bn254cs "github.com/consensys/gnark/constraint/bn254"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/test/unsafekzg"
+	"golang.org/x/crypto/sha3"
+	"io/ioutil"
+	"os"
+)
+
+var (
+	PlonkPk  plonk.ProvingKey
+	PlonkVk  plonk.VerifyingKey
+	PlonkCcs = new(bn254cs.SparseR1CS)
+)
+
+// PlonkSetup compiles circuit with scs.NewBuilder and runs the universal-SRS
+// PLONK setup, proving and verifying once to sanity-check the circuit before
+// the keys are persisted.
+func PlonkSetup() error {
+	circuit, assigment, err := DoBabyBearSolve()
+	if err != nil {
+		return fmt.Errorf("fail to solve: %v", err)
+	}
+
+	fullWitness, err := frontend.NewWitness(assigment, ecc.BN254.ScalarField())
+	if err != nil {
+		return fmt.Errorf("fail to gen full witness: %v", err)
+	}
+	pubWitness, err := fullWitness.Public()
+	if err != nil {
+		return fmt.Errorf("fail to gen public witness: %v", err)
+	}
+
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, circuit)
+	if err != nil {
+		return fmt.Errorf("fail to compile frontend: %v", err)
+	}
+	PlonkCcs = ccs.(*bn254cs.SparseR1CS)
+	fmt.Printf("ccs: %d \n", ccs.GetNbConstraints())
+
+	srs, srsLagrange, err := unsafekzg.NewSRS(PlonkCcs)
+	if err != nil {
+		return fmt.Errorf("fail to gen srs: %v", err)
+	}
+
+	PlonkPk, PlonkVk, err = plonk.Setup(PlonkCcs, srs, srsLagrange)
+	if err != nil {
+		return fmt.Errorf("fail to setup plonk: %v", err)
+	}
+
+	pf, err := plonk.Prove(PlonkCcs, PlonkPk, fullWitness, backend.WithProverHashToFieldFunction(sha3.NewLegacyKeccak256()))
+	if err != nil {
+		return fmt.Errorf("fail to prove plonk: %v", err)
+	}
+
+	err = plonk.Verify(pf, PlonkVk, pubWitness, backend.WithVerifierHashToFieldFunction(sha3.NewLegacyKeccak256()))
+	if err != nil {
+		return fmt.Errorf("fail to verify: %v", err)
+	}
+
+	err = utils.WritePlonkProvingKey(os.Getenv("PK_PATH"), PlonkPk)
+	if err != nil {
+		return fmt.Errorf("fail to write pk: %v", err)
+	}
+
+	err = utils.WritePlonkVerifyingKey(os.Getenv("VK_PATH"), PlonkVk)
+	if err != nil {
+		return fmt.Errorf("fail to write vk: %v", err)
+	}
+	return nil
+}
+
+// PlonkProve re-solves the circuit against a fresh witness and proves it
+// against the PLONK keys loaded into PlonkPk/PlonkVk.
+func PlonkProve() error {
+	PlonkPk = plonk.NewProvingKey(ecc.BN254)
+	PlonkVk = plonk.NewVerifyingKey(ecc.BN254)
+
+	err := utils.ReadPlonkProvingKey(os.Getenv("PK_PATH"), PlonkPk)
+	if err != nil {
+		return fmt.Errorf("failed to read proving key: %v", err)
+	}
+
+	err = utils.ReadPlonkVerifyingKey(os.Getenv("VK_PATH"), PlonkVk)
+	if err != nil {
+		return fmt.Errorf("failed to read verifying key: %v", err)
+	}
+
+	circuit, assigment, err := DoBabyBearSolve()
+	if err != nil {
+		return fmt.Errorf("fail to solve: %v", err)
+	}
+
+	fullWitness, err := frontend.NewWitness(assigment, ecc.BN254.ScalarField())
+	if err != nil {
+		return fmt.Errorf("failed to get full witness: %v", err)
+	}
+	pubWitness, err := fullWitness.Public()
+	if err != nil {
+		return fmt.Errorf("failed to get public witness: %v", err)
+	}
+
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, circuit)
+	if err != nil {
+		return fmt.Errorf("fail to compile frontend: %v", err)
+	}
+	PlonkCcs = ccs.(*bn254cs.SparseR1CS)
+	fmt.Printf("ccs: %d \n", ccs.GetNbConstraints())
+
+	pf, err := plonk.Prove(PlonkCcs, PlonkPk, fullWitness, backend.WithProverHashToFieldFunction(sha3.NewLegacyKeccak256()))
+	if err != nil {
+		return fmt.Errorf("failed to prove: %v", err)
+	}
+
+	err = plonk.Verify(pf, PlonkVk, pubWitness, backend.WithVerifierHashToFieldFunction(sha3.NewLegacyKeccak256()))
+	if err != nil {
+		return fmt.Errorf("failed to verify proof: %v", err)
+	}
+
+	res, err := utils.GetPlonkOnChainProof(pf, pubWitness)
+	if err != nil {
+		return fmt.Errorf("failed to get OnChainProof: %v", err)
+	}
+
+	err = os.WriteFile(os.Getenv("PROOF_PATH"), []byte(res), 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write res, err: %v", err)
+	}
+	fmt.Println("proof written successfully")
+
+	return nil
+}
+
+// provePlonk is Prove's PLONK counterpart: it proves/verifies fullWitness/
+// pubWitness against the already-loaded PlonkCcs/PlonkPk/PlonkVk and writes
+// the on-chain proof bundle to PROOF_PATH, without re-solving the circuit
+// the way PlonkProve does.
+func provePlonk(fullWitness, pubWitness witness.Witness) error {
+	pf, err := plonk.Prove(PlonkCcs, PlonkPk, fullWitness, backend.WithProverHashToFieldFunction(sha3.NewLegacyKeccak256()))
+	if err != nil {
+		return fmt.Errorf("failed to prove: %v", err)
+	}
+
+	err = plonk.Verify(pf, PlonkVk, pubWitness, backend.WithVerifierHashToFieldFunction(sha3.NewLegacyKeccak256()))
+	if err != nil {
+		return fmt.Errorf("failed to verify proof: %v", err)
+	}
+
+	res, err := utils.GetPlonkOnChainProof(pf, pubWitness)
+	if err != nil {
+		return fmt.Errorf("failed to get OnChainProof: %v\n", err)
+	}
+
+	err = ioutil.WriteFile(os.Getenv("PROOF_PATH"), []byte(res), 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write res, err: %v", err)
+	}
+	fmt.Println("proof written successfully")
+	return nil
+}
+
+// babyBearPlonkCmd mirrors BabyBearCmd's switch but against the PLONK
+// setup/prove/export path, used when BACKEND=plonk.
+func babyBearPlonkCmd(cmd string) (err error) {
+	switch cmd {
+	case "prove":
+		err = PlonkProve()
+		if err != nil {
+			return fmt.Errorf("fail to prove: %v\n", err)
+		}
+	case "setup":
+		err = PlonkSetup()
+		if err != nil {
+			return fmt.Errorf("fail to setup: %v\n", err)
+		}
+		err = ExportPlonkSolidity()
+		if err != nil {
+			return fmt.Errorf("fail to export solidity: %v\n", err)
+		}
+	case "solve":
+		_, _, err = DoBabyBearSolve()
+		if err != nil {
+			return fmt.Errorf("fail to solve: %v\n", err)
+		}
+	case "setupAndProve":
+		err = PlonkSetup()
+		if err != nil {
+			return fmt.Errorf("fail to setup: %v\n", err)
+		}
+		err = ExportPlonkSolidity()
+		if err != nil {
+			return fmt.Errorf("fail to export solidity: %v\n", err)
+		}
+		err = PlonkProve()
+		if err != nil {
+			return fmt.Errorf("fail to prove: %v\n", err)
+		}
+	case "exportSolidity":
+		err = ExportPlonkSolidity()
+		if err != nil {
+			return fmt.Errorf("fail to export solidity: %v\n", err)
+		}
+	default:
+		return fmt.Errorf("unknown command: %s", cmd)
+	}
+	return
+}
+
+// ExportPlonkSolidity writes the PLONK Solidity verifier for the circuit
+// whose verifying key lives at VK_PATH.
+func ExportPlonkSolidity() error {
+	PlonkVk = plonk.NewVerifyingKey(ecc.BN254)
+	err := utils.ReadPlonkVerifyingKey(os.Getenv("VK_PATH"), PlonkVk)
+	if err != nil {
+		return fmt.Errorf("failed to read verifying key: %v", err)
+	}
+
+	f, err := os.Create(os.Getenv("SOLIDITY_PATH"))
+	if err != nil {
+		return fmt.Errorf("fail to create solidity file: %v", err)
+	}
+	defer f.Close()
+
+	err = PlonkVk.ExportSolidity(f)
+	if err != nil {
+		return fmt.Errorf("fail to export solidity: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/brevis-network/pico/gnark/utils"
+	"github.com/celer-network/goutils/log"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/crypto/sha3"
+	"sync"
+	"time"
+)
+
+// JobStatus is the lifecycle of one /prove request, as reported by
+// GET /prove/:id.
+type JobStatus string
+
+const (
+	JobQueued  JobStatus = "queued"
+	JobRunning JobStatus = "running"
+	JobDone    JobStatus = "done"
+	JobError   JobStatus = "error"
+)
+
+// Job is the unit of work persisted by the JobStore. WitnessJsonHex is kept
+// around so a restart can re-run a job that was still queued or running
+// when the process died; ProofData/Err record the outcome once there is one.
+type Job struct {
+	ID             string    `json:"id"`
+	Status         JobStatus `json:"status"`
+	WitnessJsonHex string    `json:"witness_json_hex"`
+	ProofData      string    `json:"proof_data,omitempty"`
+	Err            string    `json:"error,omitempty"`
+}
+
+var (
+	queueDepth = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "pico_prove_queue_depth",
+		Help: "Number of jobs waiting for a free worker.",
+	})
+	proveLatency = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "pico_prove_latency_seconds",
+		Help:    "Wall-clock time spent in groth16.Prove/plonk.Prove per job.",
+		Buckets: prometheus.ExponentialBuckets(1, 2, 12),
+	})
+)
+
+func init() {
+	prometheus.MustRegister(queueDepth, proveLatency)
+}
+
+// JobQueue is a bounded worker pool pulling job IDs off jobCh and running
+// groth16.Prove (or plonk.Prove, depending on -backend) against the
+// package-level Ccs/Pk (or PlonkCcs/PlonkPk), persisting every state
+// transition to store so a restart can tell queued/running jobs from done
+// ones and so queued work isn't lost if the process dies mid-proof.
+type JobQueue struct {
+	store  *JobStore
+	jobCh  chan string
+	workWg sync.WaitGroup
+}
+
+func NewJobQueue(store *JobStore) *JobQueue {
+	return &JobQueue{
+		store: store,
+		jobCh: make(chan string, 1024),
+	}
+}
+
+// Start launches workers workers and resumes any job left queued or
+// running by a previous instance of the process. It returns immediately;
+// the pool stops pulling new jobs once ctx is cancelled and Wait returns
+// as soon as every worker goroutine has noticed, without waiting for an
+// in-flight proof itself to finish (see run).
+func (q *JobQueue) Start(ctx context.Context, workers int) error {
+	pending, err := q.store.ListPending()
+	if err != nil {
+		return fmt.Errorf("failed to list pending jobs: %v", err)
+	}
+	for i := 0; i < workers; i++ {
+		q.workWg.Add(1)
+		go q.worker(ctx)
+	}
+	for _, id := range pending {
+		q.jobCh <- id
+		queueDepth.Inc()
+	}
+	return nil
+}
+
+func (q *JobQueue) Wait() {
+	q.workWg.Wait()
+}
+
+// Enqueue persists a freshly-queued job and hands its ID to the worker
+// pool, returning immediately so POST /prove never blocks on groth16.Prove.
+func (q *JobQueue) Enqueue(witnessJsonHex string) (string, error) {
+	id := uuid.New().String()
+	job := &Job{ID: id, Status: JobQueued, WitnessJsonHex: witnessJsonHex}
+	if err := q.store.Put(job); err != nil {
+		return "", fmt.Errorf("failed to persist job: %v", err)
+	}
+
+	select {
+	case q.jobCh <- id:
+	default:
+		return "", fmt.Errorf("prove queue is full")
+	}
+	queueDepth.Inc()
+	return id, nil
+}
+
+func (q *JobQueue) worker(ctx context.Context) {
+	defer q.workWg.Done()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id, ok := <-q.jobCh:
+			if !ok {
+				return
+			}
+			queueDepth.Dec()
+			q.run(ctx, id)
+		}
+	}
+}
+
+// proveResult is the outcome of the background goroutine run launches to do
+// the actual groth16.Prove/plonk.Prove, so run can select between it
+// finishing and ctx being cancelled instead of blocking on it directly.
+type proveResult struct {
+	res string
+	err error
+}
+
+// run drives one job to completion. gnark's groth16.Prove/plonk.Prove take
+// no context and can't be interrupted mid-proof, so the prove itself runs
+// in a background goroutine: if ctx is cancelled first, run returns
+// immediately (letting Wait() drain promptly on shutdown) leaving the job
+// at JobRunning; the goroutine's result, if it still finishes, is dropped
+// and never persisted. Start's ListPending re-enqueues JobRunning jobs on
+// the next startup, so no work is silently lost, it just isn't cancelled
+// early.
+func (q *JobQueue) run(ctx context.Context, id string) {
+	job, err := q.store.Get(id)
+	if err != nil {
+		log.Errorf("job %s: failed to load: %v", id, err)
+		return
+	}
+	if job.Status == JobDone {
+		return
+	}
+
+	job.Status = JobRunning
+	if err := q.store.Put(job); err != nil {
+		log.Errorf("job %s: failed to persist running state: %v", id, err)
+	}
+
+	inputs := utils.WitnessInput{WitnessJsonHex: job.WitnessJsonHex}
+	fullWitness, pubWitness, err := GetWitnessFromHex(inputs)
+	if err != nil {
+		q.fail(job, fmt.Errorf("failed to rebuild witness: %v", err))
+		return
+	}
+
+	proveDone := make(chan proveResult, 1)
+	start := time.Now()
+	go func() {
+		var res string
+		var err error
+		if *proverBackend == "plonk" {
+			pf, plonkErr := plonk.Prove(PlonkCcs, PlonkPk, fullWitness, backend.WithProverHashToFieldFunction(sha3.NewLegacyKeccak256()))
+			proveLatency.Observe(time.Since(start).Seconds())
+			if plonkErr != nil {
+				proveDone <- proveResult{err: fmt.Errorf("failed to prove plonk: %v", plonkErr)}
+				return
+			}
+			res, err = utils.GetPlonkOnChainProof(pf, pubWitness)
+		} else {
+			pf, groth16Err := groth16.Prove(Ccs, Pk, fullWitness, backend.WithProverHashToFieldFunction(sha3.NewLegacyKeccak256()))
+			proveLatency.Observe(time.Since(start).Seconds())
+			if groth16Err != nil {
+				proveDone <- proveResult{err: fmt.Errorf("failed to prove groth16: %v", groth16Err)}
+				return
+			}
+			if *curve == "bls12381" {
+				res, err = utils.GetAggOnChainProofBLS12381(pf, pubWitness)
+			} else {
+				res, err = utils.GetAggOnChainProof(pf, pubWitness)
+			}
+		}
+		if err != nil {
+			proveDone <- proveResult{err: fmt.Errorf("failed to get OnChainProof: %v", err)}
+			return
+		}
+		proveDone <- proveResult{res: res}
+	}()
+
+	select {
+	case <-ctx.Done():
+		log.Infof("job %s: shutting down before proof finished, leaving it running for the next startup to resume", id)
+		return
+	case r := <-proveDone:
+		if r.err != nil {
+			q.fail(job, r.err)
+			return
+		}
+		job.Status = JobDone
+		job.ProofData = r.res
+		if err := q.store.Put(job); err != nil {
+			log.Errorf("job %s: failed to persist done state: %v", id, err)
+		}
+	}
+}
+
+func (q *JobQueue) fail(job *Job, cause error) {
+	job.Status = JobError
+	job.Err = cause.Error()
+	if err := q.store.Put(job); err != nil {
+		log.Errorf("job %s: failed to persist error state: %v", job.ID, err)
+	}
+}
+
+// jobView is the subset of Job returned by GET /prove/:id: WitnessJsonHex
+// is omitted since it can be large and the client already has it.
+type jobView struct {
+	Status    JobStatus `json:"status"`
+	ProofData string    `json:"proof_data,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+func marshalJob(job *Job) ([]byte, error) {
+	return json.Marshal(jobView{Status: job.Status, ProofData: job.ProofData, Error: job.Err})
+}
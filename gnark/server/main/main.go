@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -9,43 +10,85 @@ import (
 	"github.com/brevis-network/pico/gnark/utils"
 	"github.com/celer-network/goutils/log"
 	"github.com/consensys/gnark-crypto/ecc"
-	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/plonk"
 	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/constraint"
+	bls12381cs "github.com/consensys/gnark/constraint/bls12-381"
 	bn254cs "github.com/consensys/gnark/constraint/bn254"
 	"github.com/consensys/gnark/frontend"
 	"github.com/labstack/echo"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 
-	"golang.org/x/crypto/sha3"
+	"math/big"
 	"net/http"
+	"os"
+	"os/signal"
 	"sync"
+	"syscall"
 )
 
 var (
-	httpPort = flag.Int("httpport", 9099, "http json listening port")
-	field    = flag.String("field", "kb", "field: kb, bb")
-	pkPath   = flag.String("pk", "./data/vm_pk", "path of proving key")
-	ccsPath  = flag.String("ccs", "./data/vm_ccs", "path of ccs")
-
-	Pk  = groth16.NewProvingKey(ecc.BN254)
-	Vk  = groth16.NewVerifyingKey(ecc.BN254)
-	Ccs = new(bn254cs.R1CS)
+	httpPort      = flag.Int("httpport", 9099, "http json listening port")
+	field         = flag.String("field", "kb", "field: kb, bb")
+	curve         = flag.String("curve", "bn254", "on-chain curve: bn254, bls12381")
+	proverBackend = flag.String("backend", "groth16", "proving backend: groth16, plonk")
+	pkPath        = flag.String("pk", "./data/vm_pk", "path of proving key")
+	ccsPath       = flag.String("ccs", "./data/vm_ccs", "path of ccs")
+	workers       = flag.Int("workers", 1, "size of the groth16.Prove/plonk.Prove worker pool")
+	jobDbPath     = flag.String("jobdb", "./data/jobs.db", "path of the job state BoltDB file")
+
+	Pk  groth16.ProvingKey
+	Vk  groth16.VerifyingKey
+	Ccs constraint.ConstraintSystem
+
+	PlonkPk  plonk.ProvingKey
+	PlonkVk  plonk.VerifyingKey
+	PlonkCcs constraint.ConstraintSystem
 
 	loadReady = false
+	jobQueue  *JobQueue
 )
 
 func main() {
 	flag.Parse()
 	e := echo.New()
 
-	log.Infof("use field: %s", *field)
+	log.Infof("use field: %s, curve: %s, backend: %s", *field, *curve, *proverBackend)
+
+	if *proverBackend == "plonk" {
+		if *curve != "bn254" {
+			log.Fatalf("plonk backend only supports curve bn254, got %s", *curve)
+		}
+		PlonkPk = plonk.NewProvingKey(ecc.BN254)
+		PlonkVk = plonk.NewVerifyingKey(ecc.BN254)
+		PlonkCcs = new(bn254cs.SparseR1CS)
+	} else {
+		switch *curve {
+		case "bn254":
+			Pk = groth16.NewProvingKey(ecc.BN254)
+			Vk = groth16.NewVerifyingKey(ecc.BN254)
+			Ccs = new(bn254cs.R1CS)
+		case "bls12381":
+			Pk = groth16.NewProvingKey(ecc.BLS12_381)
+			Vk = groth16.NewVerifyingKey(ecc.BLS12_381)
+			Ccs = new(bls12381cs.R1CS)
+		default:
+			log.Fatalf("invalid curve: %s", *curve)
+		}
+	}
 
 	var wg sync.WaitGroup
 	wg.Add(2)
 	go func() {
 		defer wg.Done()
 		log.Infof("start load pk")
-		err := utils.ReadProvingKey(*pkPath, Pk)
+		var err error
+		if *proverBackend == "plonk" {
+			err = utils.ReadPlonkProvingKey(*pkPath, PlonkPk)
+		} else {
+			err = utils.ReadProvingKey(*pkPath, Pk)
+		}
 		log.Infof("end load pk")
 		if err != nil {
 			log.Fatalf("fail to load pk, err: %v", err)
@@ -54,7 +97,12 @@ func main() {
 	go func() {
 		defer wg.Done()
 		log.Infof("start load ccs")
-		err := utils.ReadCcs(*ccsPath, Ccs)
+		var err error
+		if *proverBackend == "plonk" {
+			err = utils.ReadCcs(*ccsPath, PlonkCcs)
+		} else {
+			err = utils.ReadCcs(*ccsPath, Ccs)
+		}
 		log.Infof("end load ccs")
 		if err != nil {
 			log.Fatalf("fail to load ccs, err: %v", err)
@@ -63,12 +111,38 @@ func main() {
 	wg.Wait()
 	loadReady = true
 
+	jobStore, err := OpenJobStore(*jobDbPath)
+	if err != nil {
+		log.Fatalf("fail to open job store: %v", err)
+	}
+	defer jobStore.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	jobQueue = NewJobQueue(jobStore)
+	if err := jobQueue.Start(ctx, *workers); err != nil {
+		log.Fatalf("fail to start job queue: %v", err)
+	}
+
 	e.POST("/ready", Ready)
 	e.POST("/prove", Prove)
+	e.GET("/prove/:id", ProveResult)
+	e.GET("/metrics", echo.WrapHandler(promhttp.Handler()))
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigCh
+		log.Infof("shutting down, stopping the worker pool (in-flight proofs are not cancellable; they are abandoned and re-run on the next startup)")
+		cancel()
+		jobQueue.Wait()
+		if err := e.Close(); err != nil {
+			log.Errorf("fail to close echo server: %v", err)
+		}
+	}()
 
 	log.Infof("start http %s", fmt.Sprintf("0.0.0.0:%d", *httpPort))
 	echoErr := e.Start(fmt.Sprintf("0.0.0.0:%d", *httpPort))
-	if echoErr != nil {
+	if echoErr != nil && echoErr != http.ErrServerClosed {
 		log.Fatalf("fail to start echo server, err: %v", echoErr)
 	}
 }
@@ -82,37 +156,54 @@ type ProveReq struct {
 }
 
 type ProveResp struct {
-	ProofData string `json:"proof_data"`
+	JobId string `json:"job_id"`
 }
 
+// Prove enqueues the witness and returns its job_id immediately; the
+// actual groth16.Prove runs on the JobQueue worker pool so a slow BabyBear/
+// KoalaBear proof no longer holds the HTTP connection open. Poll
+// GET /prove/:id for the result.
 func Prove(c echo.Context) error {
 	payload := &utils.WitnessInput{}
 	if err := c.Bind(payload); err != nil { // here unmarshal request body into p
 		return c.String(http.StatusInternalServerError, err.Error())
 	}
 
-	fullWitness, pubWitness, err := GetWitnessFromHex(*payload)
+	jobId, err := jobQueue.Enqueue(payload.WitnessJsonHex)
 	if err != nil {
 		return c.String(http.StatusInternalServerError, err.Error())
 	}
-	pf, err := groth16.Prove(Ccs, Pk, fullWitness, backend.WithProverHashToFieldFunction(sha3.NewLegacyKeccak256()))
+
+	return json.NewEncoder(c.Response()).Encode(&ProveResp{JobId: jobId})
+}
+
+// ProveResult reports the status of a job enqueued by Prove, including the
+// on-chain proof bundle once Status is "done".
+func ProveResult(c echo.Context) error {
+	job, err := jobQueue.store.Get(c.Param("id"))
 	if err != nil {
-		return fmt.Errorf("fail to prove groth16: %v", err)
+		return c.String(http.StatusNotFound, err.Error())
 	}
 
-	res, err := utils.GetAggOnChainProof(pf, pubWitness)
+	data, err := marshalJob(job)
 	if err != nil {
-		return fmt.Errorf("failed to get OnChainProof: %v\n", err)
+		return c.String(http.StatusInternalServerError, err.Error())
 	}
+	return c.JSONBlob(http.StatusOK, data)
+}
 
-	return json.NewEncoder(c.Response()).Encode(res)
+func outerScalarField() *big.Int {
+	if *curve == "bls12381" {
+		return ecc.BLS12_381.ScalarField()
+	}
+	return ecc.BN254.ScalarField()
 }
 
 func GetWitnessFromHex(inputs utils.WitnessInput) (fullWitness witness.Witness, pubWitness witness.Witness, err error) {
 	if *field == "kb" {
 		assigment := koalabear_verifier.NewCircuit(inputs)
 
-		fullWitness, err = frontend.NewWitness(assigment, ecc.BN254.ScalarField())
+		fullWitness, err = frontend.NewWitness(assigment, outerScalarField())
 		if err != nil {
 			return
 		}
@@ -123,7 +214,7 @@ func GetWitnessFromHex(inputs utils.WitnessInput) (fullWitness witness.Witness,
 	} else if *field == "bb" {
 		assigment := babybear_verifier.NewCircuit(inputs)
 
-		fullWitness, err = frontend.NewWitness(assigment, ecc.BN254.ScalarField())
+		fullWitness, err = frontend.NewWitness(assigment, outerScalarField())
 		if err != nil {
 			return
 		}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"go.etcd.io/bbolt"
+	"time"
+)
+
+var jobsBucket = []byte("jobs")
+
+// JobStore persists Job records in a single BoltDB file so queued or
+// in-flight jobs survive a server restart instead of silently vanishing.
+type JobStore struct {
+	db *bbolt.DB
+}
+
+func OpenJobStore(path string) (*JobStore, error) {
+	db, err := bbolt.Open(path, 0600, &bbolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open job store %s: %v", path, err)
+	}
+	err = db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(jobsBucket)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to init job bucket: %v", err)
+	}
+	return &JobStore{db: db}, nil
+}
+
+func (s *JobStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *JobStore) Put(job *Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to marshal job: %v", err)
+	}
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(jobsBucket).Put([]byte(job.ID), data)
+	})
+}
+
+func (s *JobStore) Get(id string) (*Job, error) {
+	var job Job
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		data := tx.Bucket(jobsBucket).Get([]byte(id))
+		if data == nil {
+			return fmt.Errorf("job not found: %s", id)
+		}
+		return json.Unmarshal(data, &job)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ListPending returns the IDs of every job still in the queued or running
+// state, so a restarted server can re-enqueue them instead of leaving a
+// client polling a job that will never finish.
+func (s *JobStore) ListPending() ([]string, error) {
+	var ids []string
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(jobsBucket).ForEach(func(k, v []byte) error {
+			var job Job
+			if err := json.Unmarshal(v, &job); err != nil {
+				return fmt.Errorf("failed to unmarshal job %s: %v", k, err)
+			}
+			if job.Status == JobQueued || job.Status == JobRunning {
+				ids = append(ids, job.ID)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
@@ -11,12 +11,25 @@ import (
 	"os"
 )
 
+// proofVersionPlain and proofVersionCommitted are the leading version byte
+// of the hex bundle returned by GetAggOnChainProof - the first comma-
+// separated field of the returned string, ahead of A/B/C - so a downstream
+// consumer can tell from the bundle alone whether the Commitment/
+// CommitmentPok G1 points follow the Groth16 A/B/C ones (needed whenever
+// the circuit uses frontend.Committer) without having to count fields or
+// re-derive that from the circuit itself.
+const (
+	proofVersionPlain     byte = 0
+	proofVersionCommitted byte = 1
+)
+
 type Groth16Proof struct {
+	Version       byte         `json:"version"`
 	A             [2]string    `json:"a"`
 	B             [2][2]string `json:"b"`
 	C             [2]string    `json:"c"`
-	Commitment    [2]string    `json:"commitment"`
-	CommitmentPok [2]string    `json:"commitment_pok"`
+	Commitment    [2]string    `json:"commitment,omitempty"`
+	CommitmentPok [2]string    `json:"commitment_pok,omitempty"`
 }
 
 func ReadProvingKey(filename string, pk groth16.ProvingKey) error {
@@ -67,7 +80,7 @@ func WriteVerifyingKey(filename string, vk groth16.VerifyingKey) error {
 }
 
 func GetAggOnChainProof(proof groth16.Proof, pubWitness witness.Witness) (string, error) {
-	a, b, c, _, _ := ExportProof(proof)
+	a, b, c, commitment, commitmentPok := ExportProof(proof)
 	var A [2]string
 	for i := 0; i < 2; i++ {
 		A[i] = Encode(a[i].Bytes())
@@ -85,25 +98,21 @@ func GetAggOnChainProof(proof groth16.Proof, pubWitness witness.Witness) (string
 		C[i] = Encode(c[i].Bytes())
 	}
 
-	/*var Commitment [2]string
-	for i := 0; i < 2; i++ {
-		Commitment[i] = Encode(commitment[i].Bytes())
-	}
-
-	var CommitmentPok [2]string
-	for i := 0; i < 2; i++ {
-		CommitmentPok[i] = Encode(commitmentPok[i].Bytes())
-	}*/
-
 	proofData := Groth16Proof{
-		A: A,
-		B: B,
-		C: C,
-		//Commitment:    Commitment,
-		//CommitmentPok: CommitmentPok,
+		Version: proofVersionPlain,
+		A:       A,
+		B:       B,
+		C:       C,
+	}
+	if commitment[0] != nil {
+		proofData.Version = proofVersionCommitted
+		for i := 0; i < 2; i++ {
+			proofData.Commitment[i] = Encode(commitment[i].Bytes())
+			proofData.CommitmentPok[i] = Encode(commitmentPok[i].Bytes())
+		}
 	}
 
-	var result = ""
+	var result = Encode([]byte{proofData.Version}) + ","
 	result += proofData.A[0] + ","
 	result += proofData.A[1] + ","
 	result += proofData.B[0][0] + ","
@@ -111,11 +120,14 @@ func GetAggOnChainProof(proof groth16.Proof, pubWitness witness.Witness) (string
 	result += proofData.B[1][0] + ","
 	result += proofData.B[1][1] + ","
 	result += proofData.C[0] + ","
-	result += proofData.C[1] + ","
-	//result += proofData.Commitment[0] + ","
-	//result += proofData.Commitment[1] + ","
-	//result += proofData.CommitmentPok[0] + ","
-	//result += proofData.CommitmentPok[1] + ","
+	result += proofData.C[1]
+	if proofData.Version == proofVersionCommitted {
+		result += "," + proofData.Commitment[0]
+		result += "," + proofData.Commitment[1]
+		result += "," + proofData.CommitmentPok[0]
+		result += "," + proofData.CommitmentPok[1]
+	}
+	result += ","
 
 	fmt.Printf("proofData.A[0]: %s \n", proofData.A[0])
 	fmt.Printf("proofData.A[1]: %s \n", proofData.A[1])
@@ -128,10 +140,12 @@ func GetAggOnChainProof(proof groth16.Proof, pubWitness witness.Witness) (string
 	fmt.Printf("proofData.C[0]: %s \n", proofData.C[0])
 	fmt.Printf("proofData.C[1]: %s \n", proofData.C[1])
 
-	/*fmt.Printf("proofData.Commitment[0]: %s \n", proofData.Commitment[0])
-	fmt.Printf("proofData.Commitment[1]: %s \n", proofData.Commitment[1])
-	fmt.Printf("proofData.CommitmentPok[0]: %s \n", proofData.CommitmentPok[0])
-	fmt.Printf("proofData.CommitmentPok[1]: %s \n", proofData.CommitmentPok[1])*/
+	if proofData.Version == proofVersionCommitted {
+		fmt.Printf("proofData.Commitment[0]: %s \n", proofData.Commitment[0])
+		fmt.Printf("proofData.Commitment[1]: %s \n", proofData.Commitment[1])
+		fmt.Printf("proofData.CommitmentPok[0]: %s \n", proofData.CommitmentPok[0])
+		fmt.Printf("proofData.CommitmentPok[1]: %s \n", proofData.CommitmentPok[1])
+	}
 
 	// decode witness
 
@@ -151,7 +165,10 @@ func GetAggOnChainProof(proof groth16.Proof, pubWitness witness.Witness) (string
 	return result, nil
 }
 
-// only for bn254
+// ExportProof extracts the raw Groth16 group elements for bn254 proofs.
+// commitment/commitmentPok are only populated when the circuit used
+// frontend.Committer (i.e. len(bn254Proof.Commitments) > 0) — callers must
+// check commitment[0] != nil before using them.
 func ExportProof(proof groth16.Proof) (a [2]*big.Int, b [2][2]*big.Int, c [2]*big.Int, commitment [2]*big.Int, commitmentPok [2]*big.Int) {
 	bn254Proof := proof.(*groth16_bn254.Proof)
 	// proof.Ar, proof.Bs, proof.Krs
@@ -166,11 +183,13 @@ func ExportProof(proof groth16.Proof) (a [2]*big.Int, b [2][2]*big.Int, c [2]*bi
 	c[0] = bn254Proof.Krs.X.BigInt(new(big.Int))
 	c[1] = bn254Proof.Krs.Y.BigInt(new(big.Int))
 
-	//commitment[0] = bn254Proof.Commitments[0].X.BigInt(new(big.Int))
-	//commitment[1] = bn254Proof.Commitments[0].Y.BigInt(new(big.Int))
+	if len(bn254Proof.Commitments) > 0 {
+		commitment[0] = bn254Proof.Commitments[0].X.BigInt(new(big.Int))
+		commitment[1] = bn254Proof.Commitments[0].Y.BigInt(new(big.Int))
 
-	//commitmentPok[0] = bn254Proof.CommitmentPok.X.BigInt(new(big.Int))
-	//commitmentPok[1] = bn254Proof.CommitmentPok.Y.BigInt(new(big.Int))
+		commitmentPok[0] = bn254Proof.CommitmentPok.X.BigInt(new(big.Int))
+		commitmentPok[1] = bn254Proof.CommitmentPok.Y.BigInt(new(big.Int))
+	}
 	return
 }
 
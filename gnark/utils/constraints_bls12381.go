@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"fmt"
+	bls12381_fr "github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bls12381 "github.com/consensys/gnark/backend/groth16/bls12-381"
+	"github.com/consensys/gnark/backend/witness"
+	"math/big"
+)
+
+// ExportProofBLS12381 is the BLS12-381 counterpart of ExportProof, for
+// chains/rollups whose precompiles are BLS12-381 (EIP-2537) rather than
+// BN254.
+func ExportProofBLS12381(proof groth16.Proof) (a [2]*big.Int, b [2][2]*big.Int, c [2]*big.Int, commitment [2]*big.Int, commitmentPok [2]*big.Int) {
+	bls12381Proof := proof.(*groth16_bls12381.Proof)
+	a[0] = bls12381Proof.Ar.X.BigInt(new(big.Int))
+	a[1] = bls12381Proof.Ar.Y.BigInt(new(big.Int))
+
+	b[0][0] = bls12381Proof.Bs.X.A1.BigInt(new(big.Int))
+	b[0][1] = bls12381Proof.Bs.X.A0.BigInt(new(big.Int))
+	b[1][0] = bls12381Proof.Bs.Y.A1.BigInt(new(big.Int))
+	b[1][1] = bls12381Proof.Bs.Y.A0.BigInt(new(big.Int))
+
+	c[0] = bls12381Proof.Krs.X.BigInt(new(big.Int))
+	c[1] = bls12381Proof.Krs.Y.BigInt(new(big.Int))
+
+	if len(bls12381Proof.Commitments) > 0 {
+		commitment[0] = bls12381Proof.Commitments[0].X.BigInt(new(big.Int))
+		commitment[1] = bls12381Proof.Commitments[0].Y.BigInt(new(big.Int))
+
+		commitmentPok[0] = bls12381Proof.CommitmentPok.X.BigInt(new(big.Int))
+		commitmentPok[1] = bls12381Proof.CommitmentPok.Y.BigInt(new(big.Int))
+	}
+	return
+}
+
+// GetAggOnChainProofBLS12381 is the BLS12-381 counterpart of
+// GetAggOnChainProof: same comma-separated hex bundle layout, including the
+// Commitment/CommitmentPok G1 points whenever the circuit used
+// frontend.Committer, but reading the public witness as a
+// bls12381_fr.Vector instead of bn254_fr.Vector.
+func GetAggOnChainProofBLS12381(proof groth16.Proof, pubWitness witness.Witness) (string, error) {
+	a, b, c, commitment, commitmentPok := ExportProofBLS12381(proof)
+
+	version := proofVersionPlain
+	if commitment[0] != nil {
+		version = proofVersionCommitted
+	}
+	result := Encode([]byte{version}) + ","
+	result += Encode(a[0].Bytes()) + ","
+	result += Encode(a[1].Bytes()) + ","
+	result += Encode(b[0][0].Bytes()) + ","
+	result += Encode(b[0][1].Bytes()) + ","
+	result += Encode(b[1][0].Bytes()) + ","
+	result += Encode(b[1][1].Bytes()) + ","
+	result += Encode(c[0].Bytes()) + ","
+	result += Encode(c[1].Bytes())
+	if commitment[0] != nil {
+		result += "," + Encode(commitment[0].Bytes())
+		result += "," + Encode(commitment[1].Bytes())
+		result += "," + Encode(commitmentPok[0].Bytes())
+		result += "," + Encode(commitmentPok[1].Bytes())
+	}
+
+	swVector, ok := pubWitness.Vector().(bls12381_fr.Vector)
+	if !ok {
+		return "", fmt.Errorf("public witness is not a bls12-381 vector")
+	}
+
+	for i := 0; i < len(swVector); i++ {
+		var data [32]byte
+		swVector[i].BigInt(new(big.Int)).FillBytes(data[:])
+		result += "," + Encode(data[:])
+	}
+	return result, nil
+}
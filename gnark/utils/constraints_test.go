@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bn254_fr "github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/test"
+	"golang.org/x/crypto/sha3"
+)
+
+// committerCircuit commits to Secret via frontend.Committer so the prover
+// emits the Commitments[0]/CommitmentPok group elements ExportProof is
+// meant to surface, then checks the committed value against Public.
+type committerCircuit struct {
+	Secret frontend.Variable
+	Public frontend.Variable `gnark:",public"`
+}
+
+func (c *committerCircuit) Define(api frontend.API) error {
+	committer, ok := api.Compiler().(frontend.Committer)
+	if !ok {
+		return fmt.Errorf("compiler does not support frontend.Committer")
+	}
+	commitment, err := committer.Commit(c.Secret)
+	if err != nil {
+		return err
+	}
+	api.AssertIsDifferent(commitment, 0)
+	api.AssertIsEqual(c.Secret, c.Public)
+	return nil
+}
+
+// TestGetAggOnChainProofWithCommitment round-trips a circuit that uses
+// frontend.Committer through Prove -> GetAggOnChainProof and checks the
+// exported bundle carries the extra Commitment/CommitmentPok fields the
+// on-chain verifier needs whenever the proof has them.
+func TestGetAggOnChainProofWithCommitment(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	circuit := &committerCircuit{}
+	assignment := &committerCircuit{Secret: 7, Public: 7}
+
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, circuit)
+	assert.NoError(err)
+
+	pk, vk, err := groth16.Setup(ccs)
+	assert.NoError(err)
+
+	fullWitness, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField())
+	assert.NoError(err)
+	pubWitness, err := fullWitness.Public()
+	assert.NoError(err)
+
+	pf, err := groth16.Prove(ccs, pk, fullWitness, backend.WithProverHashToFieldFunction(sha3.NewLegacyKeccak256()))
+	assert.NoError(err)
+
+	err = groth16.Verify(pf, vk, pubWitness, backend.WithVerifierHashToFieldFunction(sha3.NewLegacyKeccak256()))
+	assert.NoError(err)
+
+	_, _, _, commitment, commitmentPok := ExportProof(pf)
+	assert.NotNil(commitment[0])
+	assert.NotNil(commitmentPok[0])
+
+	bundle, err := GetAggOnChainProof(pf, pubWitness)
+	assert.NoError(err)
+
+	// version (1 field) + A/B/C (8 fields) + commitment/PoK (4 fields) +
+	// one hex field per public input; GetAggOnChainProof's unconditional ","
+	// after the proof points only separates them from the public inputs.
+	numPub := len(pubWitness.Vector().(bn254_fr.Vector))
+	wantFields := 1 + 8 + 4 + numPub
+	gotFields := len(strings.Split(bundle, ","))
+	assert.Equal(wantFields, gotFields)
+}
@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	bn254_fr "github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend/plonk"
+	plonk_bn254 "github.com/consensys/gnark/backend/plonk/bn254"
+	"github.com/consensys/gnark/backend/witness"
+)
+
+func ReadPlonkProvingKey(filename string, pk plonk.ProvingKey) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = pk.ReadFrom(f)
+	return err
+}
+
+func ReadPlonkVerifyingKey(filename string, vk plonk.VerifyingKey) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = vk.ReadFrom(f)
+	return err
+}
+
+func WritePlonkProvingKey(filename string, pk plonk.ProvingKey) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = pk.WriteTo(f)
+	return err
+}
+
+func WritePlonkVerifyingKey(filename string, vk plonk.VerifyingKey) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = vk.WriteTo(f)
+	return err
+}
+
+func encodePlonkG1(p bn254.G1Affine) (x, y string) {
+	return Encode(p.X.BigInt(new(big.Int)).Bytes()), Encode(p.Y.BigInt(new(big.Int)).Bytes())
+}
+
+func encodePlonkScalar(e bn254_fr.Element) string {
+	var data [32]byte
+	e.BigInt(new(big.Int)).FillBytes(data[:])
+	return Encode(data[:])
+}
+
+// GetPlonkOnChainProof serializes a PLONK proof into the comma-separated
+// hex layout gnark's PLONK Solidity verifier template expects: the L/R/O
+// wire commitments, the grand-product (Z) and quotient (H) commitments,
+// the opening evaluations at zeta (including the shifted Z opening), the
+// two KZG opening-proof points, and any Bsb22 (frontend.Committer)
+// commitments -- the PLONK counterpart of GetAggOnChainProof's Groth16
+// A,B,C layout. Unlike Groth16, PLONK's universal SRS means this bundle's
+// shape never changes across circuits, only the number of Bsb22
+// commitments and public inputs.
+func GetPlonkOnChainProof(proof plonk.Proof, pubWitness witness.Witness) (string, error) {
+	bn254Proof, ok := proof.(*plonk_bn254.Proof)
+	if !ok {
+		return "", fmt.Errorf("unsupported plonk proof type: %T", proof)
+	}
+
+	var result string
+	for _, p := range bn254Proof.LRO {
+		x, y := encodePlonkG1(p)
+		result += x + "," + y + ","
+	}
+
+	zx, zy := encodePlonkG1(bn254Proof.Z)
+	result += zx + "," + zy + ","
+
+	for _, p := range bn254Proof.H {
+		x, y := encodePlonkG1(p)
+		result += x + "," + y + ","
+	}
+
+	for _, v := range bn254Proof.BatchedProof.ClaimedValues {
+		result += encodePlonkScalar(v) + ","
+	}
+	result += encodePlonkScalar(bn254Proof.ZShiftedOpening.ClaimedValue) + ","
+
+	bhx, bhy := encodePlonkG1(bn254Proof.BatchedProof.H)
+	result += bhx + "," + bhy + ","
+
+	zhx, zhy := encodePlonkG1(bn254Proof.ZShiftedOpening.H)
+	result += zhx + "," + zhy + ","
+
+	for _, c := range bn254Proof.Bsb22Commitments {
+		x, y := encodePlonkG1(c)
+		result += x + "," + y + ","
+	}
+
+	swVector := pubWitness.Vector().(bn254_fr.Vector)
+	for i := 0; i < len(swVector); i++ {
+		var data [32]byte
+		swVector[i].BigInt(new(big.Int)).FillBytes(data[:])
+		if i == len(swVector)-1 {
+			result += Encode(data[:])
+		} else {
+			result += Encode(data[:]) + ","
+		}
+	}
+	return result, nil
+}
@@ -14,6 +14,12 @@ type Poseidon2BabyBearChip struct {
 	State       [16]babybear.Variable
 	bufferCount int
 
+	// squeezeReady is true exactly when State already holds a permutation
+	// output that hasn't been read yet, so Squeeze knows whether it must
+	// permute before returning lanes (otherwise it would hand back raw,
+	// un-permuted absorbed input as "hash output").
+	squeezeReady bool
+
 	api      frontend.API
 	fieldApi *babybear.Chip
 }
@@ -43,16 +49,27 @@ func NewBabyBearChip(api frontend.API) *Poseidon2BabyBearChip {
 	}
 }
 
+// Update absorbs a single field element into the current lane and permutes
+// once RATE = WIDTH-1 elements have been absorbed, mirroring
+// Poseidon2KoalaBearChip.Update so callers can hash variable-length
+// transcripts over either field with the same calling convention.
 func (p *Poseidon2BabyBearChip) Update(input babybear.Variable) {
 	p.State[p.bufferCount] = p.fieldApi.AddF(p.State[p.bufferCount], input)
 	p.bufferCount = p.bufferCount + 1
+	p.squeezeReady = false
 
-	if p.bufferCount == 15 {
+	if p.bufferCount == BABYBEAR_WIDTH-1 {
 		p.PermuteMut(&p.State)
 		p.bufferCount = 0
+		p.squeezeReady = true
 	}
 }
 
+// Finalize domain-separates the sponge by adding 1 at the current absorb
+// position (or lane 0 if nothing has been absorbed since the last
+// permutation), permutes once more, and resets the read position so a
+// following Squeeze starts at lane 0 instead of skipping the lanes Update
+// had already filled.
 func (p *Poseidon2BabyBearChip) Finalize() [16]babybear.Variable {
 	if p.bufferCount > 0 {
 		p.State[p.bufferCount] = p.fieldApi.AddF(p.State[p.bufferCount], babybear.One())
@@ -60,6 +77,8 @@ func (p *Poseidon2BabyBearChip) Finalize() [16]babybear.Variable {
 		p.State[0] = p.fieldApi.AddF(p.State[0], babybear.One())
 	}
 	p.PermuteMut(&p.State)
+	p.bufferCount = 0
+	p.squeezeReady = true
 
 	res := [16]babybear.Variable{}
 	for i := 0; i < 16; i++ {
@@ -68,6 +87,33 @@ func (p *Poseidon2BabyBearChip) Finalize() [16]babybear.Variable {
 	return res
 }
 
+// Squeeze pulls n field elements out of the sponge, permuting first if the
+// state hasn't been permuted since the last absorb (so a Squeeze called
+// right after Update without a Finalize doesn't leak the raw, un-permuted
+// input as "hash output"), then permuting again whenever the current state
+// has been fully read out, for building Fiat-Shamir transcripts that need
+// more output than a single permutation provides.
+func (p *Poseidon2BabyBearChip) Squeeze(n int) []babybear.Variable {
+	if !p.squeezeReady {
+		p.PermuteMut(&p.State)
+		p.bufferCount = 0
+		p.squeezeReady = true
+	}
+
+	out := make([]babybear.Variable, 0, n)
+	pos := p.bufferCount
+	for len(out) < n {
+		if pos == BABYBEAR_WIDTH {
+			p.PermuteMut(&p.State)
+			pos = 0
+		}
+		out = append(out, p.State[pos])
+		pos++
+	}
+	p.bufferCount = pos
+	return out
+}
+
 func (p *Poseidon2BabyBearChip) PermuteMut(state *[BABYBEAR_WIDTH]babybear.Variable) {
 	// The initial linear layer.
 	p.externalLinearLayer(state)
@@ -0,0 +1,139 @@
+package poseidon2
+
+import (
+	"github.com/brevis-network/pico/gnark/babybear"
+	"math/big"
+)
+
+// RATE is the sponge rate for the 16-wide BabyBear permutation: one lane is
+// held back as the capacity, matching Update/Finalize's bufferCount == 15
+// trigger.
+const RATE = BABYBEAR_WIDTH - 1
+
+// babybearCubicSbox selects the cheaper x^3 S-box for primes where 3 is a
+// valid permutation exponent (gcd(3, p-1) == 1). BabyBear's modulus is
+// 2^31-2^27+1, whose p-1 is divisible by 3, so x^3 doesn't permute the
+// field and this stays false; it exists so a future prime sharing this
+// file's PermuteMutBatch/sboxBatch plumbing only needs to flip the
+// constant rather than fork the round loop. (KoalaBear's p-1 = 2^24*127 is
+// not divisible by 3, so x^3 does permute that field, but the KoalaBear
+// chip does not share this file.)
+const babybearCubicSbox = false
+
+// Absorb adds multiple field elements into the sponge in one call. It packs
+// them rate-aligned against bufferCount exactly like repeated calls to
+// Update (AddF is a free linear combination in gnark, so there's no
+// ReduceSlow cost to defer across Update calls in the first place), but
+// always drives PermuteMutBatch instead of PermuteMut. Produces the same
+// digest as the equivalent Update loop; see
+// TestAbsorbMatchesUpdateDigestBabyBear.
+func (p *Poseidon2BabyBearChip) Absorb(inputs ...babybear.Variable) {
+	for _, input := range inputs {
+		p.State[p.bufferCount] = p.fieldApi.AddF(p.State[p.bufferCount], input)
+		p.bufferCount++
+		p.squeezeReady = false
+		if p.bufferCount == RATE {
+			p.PermuteMutBatch(&p.State)
+			p.bufferCount = 0
+			p.squeezeReady = true
+		}
+	}
+}
+
+// PermuteMutBatch computes the same permutation as PermuteMut, but folds
+// each external round's trailing externalLinearLayer together with the
+// next round's leading addRc into a single pass over the state via
+// externalLinearLayerWithRc, instead of two separate full-width AddF loops.
+// This does not reduce the circuit's R1CS constraint count: AddF never
+// emits constraints (it only extends a linear combination's bound
+// bookkeeping), and sboxBatch still calls the same sboxP, with the same
+// ReduceSlow/Mul calls per round, as PermuteMut's sbox. The constraint
+// count is identical to PermuteMut's (see
+// TestPoseidon2BabyBearBatchConstraints), and so is the number of AddF
+// calls: the fused pass still makes two per lane. Only the number of loops
+// over the state is smaller.
+func (p *Poseidon2BabyBearChip) PermuteMutBatch(state *[BABYBEAR_WIDTH]babybear.Variable) {
+	p.externalLinearLayer(state)
+
+	rounds := babybearNumExternalRounds + babybearNumInternalRounds
+	roundsFBeginning := babybearNumExternalRounds / 2
+
+	p.addRc(state, rc16[0])
+	p.sboxBatch(state)
+	for r := 1; r < roundsFBeginning; r++ {
+		p.externalLinearLayerWithRc(state, rc16[r])
+		p.sboxBatch(state)
+	}
+	p.externalLinearLayer(state)
+
+	pEnd := roundsFBeginning + babybearNumInternalRounds
+	for r := roundsFBeginning; r < pEnd; r++ {
+		state[0] = p.fieldApi.AddF(state[0], rc16[r][0])
+		state[0] = p.sboxPBatch(state[0])
+		p.diffusionPermuteMut(state)
+	}
+
+	p.addRc(state, rc16[pEnd])
+	p.sboxBatch(state)
+	for r := pEnd + 1; r < rounds; r++ {
+		p.externalLinearLayerWithRc(state, rc16[r])
+		p.sboxBatch(state)
+	}
+	p.externalLinearLayer(state)
+}
+
+// externalLinearLayerWithRc runs the 4x4 MDS layer like externalLinearLayer,
+// but folds the round-constant addition into the same final loop that adds
+// the cross-chunk sums. Each lane still takes two AddF calls (sums plus rc,
+// then the lane plus that), the same count as externalLinearLayer followed
+// by addRc, so neither the constraint count nor the number of AddF calls is
+// reduced.
+func (p *Poseidon2BabyBearChip) externalLinearLayerWithRc(state *[BABYBEAR_WIDTH]babybear.Variable, rc [BABYBEAR_WIDTH]babybear.Variable) {
+	for i := 0; i < BABYBEAR_WIDTH; i += 4 {
+		p.mdsLightPermutation4x4(state[i : i+4])
+	}
+
+	sums := [4]babybear.Variable{
+		state[0],
+		state[1],
+		state[2],
+		state[3],
+	}
+	for i := 4; i < BABYBEAR_WIDTH; i += 4 {
+		sums[0] = p.fieldApi.AddF(sums[0], state[i])
+		sums[1] = p.fieldApi.AddF(sums[1], state[i+1])
+		sums[2] = p.fieldApi.AddF(sums[2], state[i+2])
+		sums[3] = p.fieldApi.AddF(sums[3], state[i+3])
+	}
+
+	for i := 0; i < BABYBEAR_WIDTH; i++ {
+		state[i] = p.fieldApi.AddF(state[i], p.fieldApi.AddF(sums[i%4], rc[i]))
+	}
+}
+
+func (p *Poseidon2BabyBearChip) sboxBatch(state *[BABYBEAR_WIDTH]babybear.Variable) {
+	for i := 0; i < BABYBEAR_WIDTH; i++ {
+		state[i] = p.sboxPBatch(state[i])
+	}
+}
+
+func (p *Poseidon2BabyBearChip) sboxPBatch(input babybear.Variable) babybear.Variable {
+	if babybearCubicSbox {
+		return p.sboxPCubic(input)
+	}
+	return p.sboxP(input)
+}
+
+// sboxPCubic is the x^3 S-box enabled by babybearCubicSbox; it's unused for
+// BabyBear today but kept alongside sboxP so a future field with a
+// 3-friendly modulus reuses the same PermuteMutBatch.
+func (p *Poseidon2BabyBearChip) sboxPCubic(input babybear.Variable) babybear.Variable {
+	inputCpy := p.fieldApi.ReduceSlow(p.fieldApi.AddF(input, babybear.NewFConst("0")))
+	inputValue := inputCpy.Value
+	i2 := p.api.Mul(inputValue, inputValue)
+	i3 := p.api.Mul(i2, inputValue)
+	return p.fieldApi.ReduceSlow(babybear.Variable{
+		Value:      i3,
+		UpperBound: new(big.Int).Exp(new(big.Int).SetUint64(2013265921), new(big.Int).SetUint64(3), new(big.Int).SetUint64(0)),
+	})
+}